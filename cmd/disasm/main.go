@@ -0,0 +1,130 @@
+// Command disasm dumps the code in a ROM or program file as 6502 assembly.
+//
+//	disasm [flags] file
+//
+// The file can be an iNES ROM, whose PRG ROM is loaded at $8000 (mirrored to $C000 if it's 16KiB),
+// Intel HEX (.hex/.ihx), S-records (.s19/.s28/.s37/.srec) or a raw binary loaded at -origin.
+// The reset, NMI and IRQ vectors are labelled when they're in the file, and -labels adds more from a file with
+// lines like "C000 reset".
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"nes/pkg/cpu"
+	"nes/pkg/disasm"
+)
+
+func main() {
+	origin := flag.String("origin", "8000", "where a raw binary is loaded")
+	start := flag.String("start", "", "the first address to disassemble, by default the start of the file")
+	end := flag.String("end", "", "the last address to disassemble, by default the end of the file")
+	variant := flag.String("variant", "2A03", "the CPU variant: 2A03, 6502 or 65C02")
+	labelFile := flag.String("labels", "", "a file of labels, one \"ADDR NAME\" per line")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: disasm [flags] file\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	log.SetFlags(0)
+
+	v, err := cpu.ParseVariant(*variant)
+	if err != nil {
+		log.Fatal(err)
+	}
+	org, err := cpu.ParseAddr(*origin)
+	if err != nil {
+		log.Fatalf("-origin: %v", err)
+	}
+	data, err := os.ReadFile(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	prog, err := cpu.ParseProgram(flag.Arg(0), data, org)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mem := &cpu.RAM{}
+	loaded := map[uint16]bool{}
+	lo, hi := uint16(0xFFFF), uint16(0)
+	for _, seg := range prog.Segments {
+		for i, b := range seg.Data {
+			addr := seg.Addr + uint16(i)
+			mem.Write(addr, b)
+			loaded[addr] = true
+			lo, hi = min(lo, addr), max(hi, addr)
+		}
+	}
+	if len(loaded) == 0 {
+		log.Fatalf("%v: no code", flag.Arg(0))
+	}
+
+	d := disasm.New(disasm.WithVariant(v))
+	vectors := []struct {
+		name string
+		addr uint16
+	}{{"nmi", 0xFFFA}, {"reset", 0xFFFC}, {"irq", 0xFFFE}}
+	for _, vec := range vectors {
+		if loaded[vec.addr] && loaded[vec.addr+1] {
+			d.SetLabel(uint16(mem.Peek(vec.addr+1))<<8|uint16(mem.Peek(vec.addr)), vec.name)
+		}
+	}
+	if *labelFile != "" {
+		if err := readLabels(d, *labelFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *start != "" {
+		if lo, err = cpu.ParseAddr(*start); err != nil {
+			log.Fatalf("-start: %v", err)
+		}
+	}
+	if *end != "" {
+		if hi, err = cpu.ParseAddr(*end); err != nil {
+			log.Fatalf("-end: %v", err)
+		}
+	}
+	w := bufio.NewWriter(os.Stdout)
+	if err := d.Write(w, d.Range(mem, lo, hi)); err != nil {
+		log.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readLabels adds the labels in path to d.
+func readLabels(d *disasm.Disassembler, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) != 2 {
+			return fmt.Errorf("%v:%v: want ADDR NAME", path, line)
+		}
+		addr, err := cpu.ParseAddr(fields[0])
+		if err != nil {
+			return fmt.Errorf("%v:%v: %w", path, line, err)
+		}
+		d.SetLabel(addr, fields[1])
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,96 @@
+// Command nesmon is a machine-language monitor for the CPU: load a program, step through it, set breakpoints,
+// and look at and change the registers and memory.
+//
+//	nesmon [flags] [file]
+//
+// The file is loaded the same way as by disasm and the CPU is reset into it. Type h for the commands.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"nes/pkg/cpu"
+	"nes/pkg/disasm"
+)
+
+func main() {
+	origin := flag.String("origin", "8000", "where a raw binary is loaded")
+	variant := flag.String("variant", "2A03", "the CPU variant: 2A03, 6502 or 65C02")
+	accurate := flag.Bool("accurate", false, "use the bus-cycle-accurate executor, not for the 65C02")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: nesmon [flags] [file]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	log.SetFlags(0)
+
+	v, err := cpu.ParseVariant(*variant)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *accurate && v == cpu.Variant65C02 {
+		log.Fatalf("-accurate: the accurate executor doesn't support the %v", v)
+	}
+	org, err := cpu.ParseAddr(*origin)
+	if err != nil {
+		log.Fatalf("-origin: %v", err)
+	}
+	c := cpu.New(cpu.WithVariant(v), cpu.WithAccurate(*accurate))
+	if flag.NArg() > 0 {
+		data, err := os.ReadFile(flag.Arg(0))
+		if err != nil {
+			log.Fatal(err)
+		}
+		prog, err := cpu.ParseProgram(flag.Arg(0), data, org)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := c.Load(prog); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// ctrl-C stops whatever's running instead of quitting
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	m := &monitor{
+		cpu:          c,
+		disassembler: disasm.New(disasm.WithVariant(v)),
+		out:          os.Stdout,
+		origin:       org,
+		breakpoints:  map[uint16]bool{},
+		context: func() (context.Context, context.CancelFunc) {
+			select {
+			case <-interrupts: // one from the prompt
+			default:
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				select {
+				case <-interrupts:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+			return ctx, cancel
+		},
+	}
+
+	m.state()
+	input := bufio.NewScanner(os.Stdin)
+	for fmt.Print("> "); input.Scan(); fmt.Print("> ") {
+		if err := m.exec(input.Text()); errors.Is(err, errQuit) {
+			return
+		} else if err != nil {
+			fmt.Println("?", err)
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,449 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"nes/pkg/cpu"
+	"nes/pkg/disasm"
+)
+
+// monitor runs the commands typed into nesmon against a CPU.
+type monitor struct {
+	cpu          *cpu.CPU
+	disassembler *disasm.Disassembler
+	out          io.Writer
+	// breakpoints mirrors the CPU's so they can be listed.
+	breakpoints map[uint16]bool
+	// dump is where a memory dump without an address carries on from.
+	dump uint16
+	// origin is where load puts a raw binary without an address, the -origin flag.
+	origin uint16
+	// context gives the context a command that runs the CPU stops on, which main cancels on ctrl-C.
+	context func() (context.Context, context.CancelFunc)
+}
+
+// command is one of the monitor's commands. run gets the arguments after the name.
+type command struct {
+	names []string
+	usage string
+	help  string
+	run   func(m *monitor, args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{[]string{"s", "step"}, "s [n]", "step n instructions, 1 by default", (*monitor).step},
+		{[]string{"n", "next"}, "n", "step, but run a JSR's subroutine through to its return", (*monitor).next},
+		{[]string{"f", "finish"}, "f", "run until the current subroutine or interrupt handler returns", (*monitor).finish},
+		{[]string{"g", "go"}, "g [addr]", "run from addr or the pc until a breakpoint or ctrl-C", (*monitor).goCmd},
+		{[]string{"r", "regs"}, "r [reg=val...]", "show the registers, or set them, e.g. r a=12 pc=c000", (*monitor).regs},
+		{[]string{"m", "mem"}, "m [start [end]]", "hex dump memory, 128 bytes by default", (*monitor).mem},
+		{[]string{"fill"}, "fill start end byte...", "fill memory with a repeating pattern of bytes", (*monitor).fill},
+		{[]string{"load"}, "load file [addr]", "load a program into memory, a raw binary at addr or -origin, and move the pc to its start", (*monitor).load},
+		{[]string{"save"}, "save file start end", "save memory to a raw binary", (*monitor).save},
+		{[]string{"d", "dis"}, "d [addr [n]]", "disassemble n instructions from addr, or around the pc", (*monitor).dis},
+		{[]string{"b", "break"}, "b [addr]", "set a breakpoint, or list them", (*monitor).breakCmd},
+		{[]string{"bc", "clear"}, "bc addr|*", "clear a breakpoint, or all of them", (*monitor).clear},
+		{[]string{"reset"}, "reset", "run the reset sequence", (*monitor).reset},
+		{[]string{"h", "help", "?"}, "h", "list the commands", (*monitor).help},
+	}
+}
+
+// errQuit is returned by exec for the quit command.
+var errQuit = errors.New("quit")
+
+// exec runs a line of input.
+func (m *monitor) exec(line string) error {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+	name := strings.ToLower(fields[0])
+	if name == "q" || name == "quit" {
+		return errQuit
+	}
+	for _, c := range commands {
+		if slices.Contains(c.names, name) {
+			return c.run(m, fields[1:])
+		}
+	}
+	return fmt.Errorf("unknown command %v, h lists them", fields[0])
+}
+
+// state prints the next instruction and the registers.
+func (m *monitor) state() {
+	fmt.Fprintln(m.out, m.cpu.TraceLine())
+}
+
+func (m *monitor) step(args []string) error {
+	n := 1
+	if len(args) > 0 {
+		var err error
+		if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+			return fmt.Errorf("bad count %v", args[0])
+		}
+	}
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			m.state()
+		}
+		info, err := m.cpu.Step()
+		if err != nil {
+			return err
+		}
+		if info.Interrupt {
+			fmt.Fprintf(m.out, "%v to $%04X\n", info.Name, m.cpu.PC())
+		}
+	}
+	m.state()
+	return nil
+}
+
+// opJSR is the opcode of JSR on every variant.
+const opJSR = 0x20
+
+func (m *monitor) next([]string) error {
+	if m.cpu.Peek(m.cpu.PC()) != opJSR {
+		return m.step(nil)
+	}
+	ret, s := m.cpu.PC()+3, m.cpu.Registers().S
+	// it's back when the pc is after the JSR with the stack where it was, which a recursive call to the same place won't be
+	return m.runUntil(func(cpu.StepInfo) bool {
+		return m.cpu.PC() == ret && m.cpu.Registers().S == s
+	})
+}
+
+func (m *monitor) finish([]string) error {
+	s := m.cpu.Registers().S
+	return m.runUntil(func(info cpu.StepInfo) bool {
+		// the return pulls the stack above where it was when we started
+		return (info.Name == "RTS" || info.Name == "RTI") && int8(m.cpu.Registers().S-s) > 0
+	})
+}
+
+// runUntil steps until done says so, stopping early at breakpoints, on errors and on ctrl-C.
+func (m *monitor) runUntil(done func(cpu.StepInfo) bool) error {
+	ctx, cancel := m.context()
+	defer cancel()
+	for n := 0; ; n++ {
+		if n > 0 && m.breakpoints[m.cpu.PC()] {
+			fmt.Fprintf(m.out, "breakpoint at $%04X\n", m.cpu.PC())
+			break
+		}
+		if n%1024 == 0 && ctx.Err() != nil {
+			fmt.Fprintln(m.out, "interrupted")
+			break
+		}
+		info, err := m.cpu.Step()
+		if err != nil {
+			m.state()
+			return err
+		}
+		if m.cpu.Halted() {
+			fmt.Fprintf(m.out, "jammed at $%04X\n", info.PC)
+			break
+		}
+		if done(info) {
+			break
+		}
+	}
+	m.state()
+	return nil
+}
+
+func (m *monitor) goCmd(args []string) error {
+	if len(args) > 0 {
+		addr, err := cpu.ParseAddr(args[0])
+		if err != nil {
+			return err
+		}
+		m.cpu.SetPC(addr)
+	}
+	ctx, cancel := m.context()
+	defer cancel()
+	stop := m.cpu.Run(ctx, 0)
+	fmt.Fprintln(m.out, stop)
+	m.state()
+	return nil
+}
+
+func (m *monitor) regs(args []string) error {
+	regs := m.cpu.Registers()
+	for _, arg := range args {
+		name, val, ok := strings.Cut(arg, "=")
+		if !ok {
+			return fmt.Errorf("want reg=val, not %v", arg)
+		}
+		n, err := strconv.ParseUint(strings.TrimPrefix(val, "$"), 16, 16)
+		if err != nil {
+			return fmt.Errorf("bad value %v", val)
+		}
+		name = strings.ToLower(name)
+		if name != "pc" && n > 0xFF {
+			return fmt.Errorf("%v is only a byte", name)
+		}
+		switch name {
+		case "pc":
+			regs.PC = uint16(n)
+		case "a":
+			regs.A = byte(n)
+		case "x":
+			regs.X = byte(n)
+		case "y":
+			regs.Y = byte(n)
+		case "s", "sp":
+			regs.S = byte(n)
+		case "p":
+			regs.P = cpu.Status(n)
+		default:
+			return fmt.Errorf("unknown register %v", name)
+		}
+	}
+	m.cpu.SetRegisters(regs)
+	fmt.Fprintf(m.out, "PC:%04X A:%02X X:%02X Y:%02X S:%02X P:%02X %v CYC:%v\n",
+		regs.PC, regs.A, regs.X, regs.Y, regs.S, byte(regs.P), regs.P, m.cpu.Cycles())
+	return nil
+}
+
+func (m *monitor) mem(args []string) error {
+	start, err := addrArg(args, 0, m.dump)
+	if err != nil {
+		return err
+	}
+	end, err := addrArg(args, 1, start+0x7F)
+	if err != nil {
+		return err
+	}
+	if end < start {
+		end = 0xFFFF
+	}
+	for row := int(start) &^ 0xF; row <= int(end); row += 0x10 {
+		hex, text := make([]string, 16), make([]byte, 16)
+		for i := range 16 {
+			addr := row + i
+			if addr < int(start) || addr > int(end) {
+				hex[i], text[i] = "  ", ' '
+				continue
+			}
+			b := m.cpu.Peek(uint16(addr))
+			hex[i], text[i] = fmt.Sprintf("%02X", b), '.'
+			if b >= 0x20 && b < 0x7F {
+				text[i] = b
+			}
+		}
+		fmt.Fprintf(m.out, "%04X  %v  %v  |%s|\n", row, strings.Join(hex[:8], " "), strings.Join(hex[8:], " "), text)
+	}
+	m.dump = end + 1
+	return nil
+}
+
+func (m *monitor) fill(args []string) error {
+	if len(args) < 3 {
+		return fmt.Errorf("usage: fill start end byte...")
+	}
+	start, end, err := parseRange(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	pattern := []byte{}
+	for _, arg := range args[2:] {
+		b, err := strconv.ParseUint(strings.TrimPrefix(arg, "$"), 16, 8)
+		if err != nil {
+			return fmt.Errorf("bad byte %v", arg)
+		}
+		pattern = append(pattern, byte(b))
+	}
+	for addr, i := int(start), 0; addr <= int(end); addr, i = addr+1, i+1 {
+		m.cpu.Poke(uint16(addr), pattern[i%len(pattern)])
+	}
+	return nil
+}
+
+func (m *monitor) load(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: load file [addr]")
+	}
+	origin, err := addrArg(args, 1, m.origin)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	prog, err := cpu.ParseProgram(args[0], data, origin)
+	if err != nil {
+		return err
+	}
+	for _, seg := range prog.Segments {
+		for i, b := range seg.Data {
+			m.cpu.Poke(seg.Addr+uint16(i), b)
+		}
+		fmt.Fprintf(m.out, "loaded $%04X-$%04X\n", seg.Addr, int(seg.Addr)+len(seg.Data)-1)
+	}
+	// the vectors are left alone so the program can be loaded over a running one
+	if prog.Reset != nil {
+		regs := m.cpu.Registers()
+		regs.PC = *prog.Reset
+		m.cpu.SetRegisters(regs)
+		fmt.Fprintf(m.out, "pc $%04X\n", regs.PC)
+	}
+	return nil
+}
+
+func (m *monitor) save(args []string) error {
+	if len(args) != 3 {
+		return fmt.Errorf("usage: save file start end")
+	}
+	start, end, err := parseRange(args[1], args[2])
+	if err != nil {
+		return err
+	}
+	data := []byte{}
+	for addr := int(start); addr <= int(end); addr++ {
+		data = append(data, m.cpu.Peek(uint16(addr)))
+	}
+	return os.WriteFile(args[0], data, 0o644)
+}
+
+// disBack is how far before the pc disassembling around it looks for an instruction that leads to it.
+const disBack = 3
+
+func (m *monitor) dis(args []string) error {
+	pc := m.cpu.PC()
+	start, err := addrArg(args, 0, m.before(pc, disBack))
+	if err != nil {
+		return err
+	}
+	n := 10
+	if len(args) > 1 {
+		if n, err = strconv.Atoi(args[1]); err != nil || n < 1 {
+			return fmt.Errorf("bad count %v", args[1])
+		}
+	}
+	addr := start
+	for range n {
+		in := m.disassembler.Decode(m.cpu, addr)
+		mark := "  "
+		if addr == pc {
+			mark = "> "
+		} else if m.breakpoints[addr] {
+			mark = "* "
+		}
+		fmt.Fprintln(m.out, mark+m.disassembler.Line(in))
+		addr += uint16(len(in.Bytes))
+	}
+	return nil
+}
+
+// before finds where to start disassembling to show up to n instructions before addr. Machine code can't be
+// decoded backwards for sure, so it tries every start up to 3 bytes an instruction back that decodes forwards right
+// onto addr, and takes the furthest back of the ones with the fewest illegal opcodes and BRKs, which are the likeliest
+// to be data or empty memory.
+func (m *monitor) before(addr uint16, n int) uint16 {
+	best, bestIllegal := addr, 0
+	for back := 3 * n; back >= 1; back-- {
+		if back > int(addr) {
+			continue
+		}
+		start := addr - uint16(back)
+		at, count, illegal := start, 0, 0
+		for at < addr && at >= start {
+			in := m.disassembler.Decode(m.cpu, at)
+			if in.Opcode.Illegal || in.Opcode.Opcode == 0x00 {
+				illegal++
+			}
+			at += uint16(len(in.Bytes))
+			count++
+		}
+		if at == addr && count <= n && (best == addr || illegal < bestIllegal) {
+			best, bestIllegal = start, illegal
+		}
+	}
+	return best
+}
+
+func (m *monitor) breakCmd(args []string) error {
+	if len(args) == 0 {
+		addrs := []uint16{}
+		for addr := range m.breakpoints {
+			addrs = append(addrs, addr)
+		}
+		slices.Sort(addrs)
+		for _, addr := range addrs {
+			fmt.Fprintf(m.out, "$%04X\n", addr)
+		}
+		return nil
+	}
+	addr, err := cpu.ParseAddr(args[0])
+	if err != nil {
+		return err
+	}
+	m.breakpoints[addr] = true
+	m.cpu.SetBreakpoint(addr)
+	return nil
+}
+
+func (m *monitor) clear(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: bc addr|*")
+	}
+	if args[0] == "*" {
+		for addr := range m.breakpoints {
+			m.cpu.ClearBreakpoint(addr)
+		}
+		clear(m.breakpoints)
+		return nil
+	}
+	addr, err := cpu.ParseAddr(args[0])
+	if err != nil {
+		return err
+	}
+	delete(m.breakpoints, addr)
+	m.cpu.ClearBreakpoint(addr)
+	return nil
+}
+
+func (m *monitor) reset([]string) error {
+	m.cpu.Reset()
+	m.state()
+	return nil
+}
+
+func (m *monitor) help([]string) error {
+	for _, c := range commands {
+		fmt.Fprintf(m.out, "  %-24v %v\n", c.usage, c.help)
+	}
+	fmt.Fprintf(m.out, "  %-24v %v\n", "q", "quit")
+	return nil
+}
+
+// addrArg parses the address in args[i], or gives def if there isn't one.
+func addrArg(args []string, i int, def uint16) (uint16, error) {
+	if i >= len(args) {
+		return def, nil
+	}
+	return cpu.ParseAddr(args[i])
+}
+
+func parseRange(startArg, endArg string) (start, end uint16, err error) {
+	if start, err = cpu.ParseAddr(startArg); err != nil {
+		return 0, 0, err
+	}
+	if end, err = cpu.ParseAddr(endArg); err != nil {
+		return 0, 0, err
+	}
+	if end < start {
+		return 0, 0, fmt.Errorf("$%04X is before $%04X", end, start)
+	}
+	return start, end, nil
+}
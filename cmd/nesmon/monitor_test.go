@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"nes/pkg/asm"
+	"nes/pkg/cpu"
+	"nes/pkg/disasm"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testProgram = `
+	.org $0200
+main:
+	LDX #3
+	JSR count
+	JSR count
+	NOP
+done:
+	JMP done
+
+count:
+	INC $10
+	JSR inner
+	RTS
+
+inner:
+	INY
+	RTS
+`
+
+func TestMonitor(t *testing.T) {
+	Convey("the monitor", t, func() {
+		prog := asm.MustAssemble(testProgram)
+		c := cpu.New()
+		So(c.Load(&prog.Program), ShouldBeNil)
+		var out strings.Builder
+		m := &monitor{
+			cpu:          c,
+			disassembler: disasm.New(),
+			out:          &out,
+			origin:       0x8000,
+			breakpoints:  map[uint16]bool{},
+			context: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+		}
+		exec := func(line string) string {
+			out.Reset()
+			So(m.exec(line), ShouldBeNil)
+			return out.String()
+		}
+		pc := func() uint16 { return c.PC() }
+
+		Convey("steps", func() {
+			So(exec("s"), ShouldStartWith, "0202  20 0C 02  JSR $020C ")
+			So(c.Registers().X, ShouldEqual, 3)
+			exec("s 2")
+			So(pc(), ShouldEqual, prog.Symbols["count"]+2)
+		})
+
+		Convey("steps over subroutines", func() {
+			exec("s")
+			exec("n")
+			So(pc(), ShouldEqual, 0x0205)
+			So(c.Peek(0x10), ShouldEqual, 1)
+			So(c.Registers().Y, ShouldEqual, 1)
+			exec("n")
+			exec("n") // NOP isn't a JSR
+			So(pc(), ShouldEqual, prog.Symbols["done"])
+		})
+
+		Convey("runs until the subroutine returns", func() {
+			exec("s 4") // into count and then inner
+			So(pc(), ShouldEqual, prog.Symbols["inner"])
+			exec("f")
+			So(pc(), ShouldEqual, prog.Symbols["count"]+5)
+			exec("f")
+			So(pc(), ShouldEqual, 0x0205)
+		})
+
+		Convey("stops at breakpoints", func() {
+			exec("b 20e")
+			exec("b $0205")
+			So(exec("b"), ShouldEqual, "$0205\n$020E\n")
+			So(exec("g"), ShouldStartWith, "breakpoint at 0x020e")
+			exec("bc 20e")
+			So(exec("g"), ShouldStartWith, "breakpoint at 0x0205")
+			exec("b 212")
+			So(exec("n"), ShouldStartWith, "breakpoint at $0212") // the subroutine hits one
+			exec("bc *")
+			So(exec("b"), ShouldEqual, "")
+		})
+
+		Convey("shows and sets registers", func() {
+			So(exec("r a=12 x=$34 pc=c000 p=e7"), ShouldEqual, "PC:C000 A:12 X:34 Y:00 S:FA P:E7 NVUbdIZC CYC:14\n")
+			So(c.Registers().A, ShouldEqual, 0x12)
+			out.Reset()
+			So(m.exec("r a=100"), ShouldBeError, "a is only a byte")
+			So(m.exec("r q=1"), ShouldBeError, "unknown register q")
+		})
+
+		Convey("dumps, fills, saves and loads memory", func() {
+			exec("fill 300 30a 48 49")
+			So(exec("m 300 30b"), ShouldEqual, "0300  48 49 48 49 48 49 48 49  48 49 48 00              |HIHIHIHIHIH.    |\n")
+			So(exec("m"), ShouldStartWith, "0300                                       00 00 00 00  |            ....|\n")
+
+			path := filepath.Join(t.TempDir(), "mem.bin")
+			exec("save " + path + " 300 303")
+			exec("fill 300 303 0")
+			So(exec("load "+path+" 300"), ShouldEqual, "loaded $0300-$0303\npc $0300\n")
+			So(c.Peek(0x0303), ShouldEqual, 0x49)
+			So(c.Registers().PC, ShouldEqual, 0x0300)
+			So(exec("load "+path), ShouldEqual, "loaded $8000-$8003\npc $8000\n") // at -origin
+			data, err := os.ReadFile(path)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "HIHI")
+		})
+
+		Convey("disassembles around the pc", func() {
+			exec("s 2")
+			So(exec("d"), ShouldStartWith, ""+
+				"  0205  20 0C 02  JSR $020C\n"+
+				"  0208  EA        NOP\n"+
+				"  0209  4C 09 02  JMP $0209\n"+
+				"> 020C  E6 10     INC $10\n"+
+				"  020E  20 12 02  JSR $0212\n")
+			So(exec("d 200 1"), ShouldEqual, "  0200  A2 03     LDX #$03\n")
+		})
+
+		Convey("resets", func() {
+			exec("s 3")
+			So(exec("reset"), ShouldStartWith, "0200  A2 03     LDX #$03 ")
+		})
+
+		Convey("rejects what it doesn't know", func() {
+			So(m.exec("zap"), ShouldBeError, "unknown command zap, h lists them")
+			So(m.exec("q"), ShouldEqual, errQuit)
+			So(exec("h"), ShouldContainSubstring, "step n instructions")
+		})
+	})
+}
@@ -0,0 +1,432 @@
+// Package asm is a two-pass 6502 assembler, so test programs and small fixtures can be written in assembly instead of opcode bytes.
+//
+// Source has one statement per line, and comments start with a semicolon:
+//
+//	start:             a label is the address of whatever comes after it
+//	@loop:             a local label belongs to the label before it, so every routine can have its own @loop
+//	COUNT = 8          a constant, which can be any expression, see the expression syntax in expr.go
+//	    LDA ($20),Y    an instruction, in the usual syntax for each addressing mode
+//	    .org $C000     carry on assembling at an address, it starts at 0
+//	    .byte 1, "hi"  bytes and strings
+//	    .word start    little endian words
+//	    .res 16, $FF   reserve some bytes, filled with 0 or the fill byte
+//
+// The first pass works out the address of every label and the second one writes the bytes. An address that's known in
+// the first pass and fits in a byte gets a zero page mode, a forward reference always gets the absolute one.
+package asm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"nes/pkg/cpu"
+)
+
+// Program is what the source assembled to.
+type Program struct {
+	// Program has the bytes of each .org as a segment so it can be loaded with [cpu.CPU.Load].
+	// Reset is the address of the first byte, or nil if the source sets the reset vector itself.
+	cpu.Program
+	// Symbols are the labels and constants. A local label is its label's name then its own, e.g. "start@loop".
+	Symbols map[string]uint16
+}
+
+// Origin is the lowest address the program has bytes at.
+func (p *Program) Origin() uint16 {
+	origin := uint16(0xFFFF)
+	for _, seg := range p.Segments {
+		origin = min(origin, seg.Addr)
+	}
+	return origin
+}
+
+// Bytes is the program as one image from [Program.Origin] to its last byte, with 0 in any gaps between segments.
+func (p *Program) Bytes() []byte {
+	origin := int(p.Origin())
+	image := []byte{}
+	for _, seg := range p.Segments {
+		if end := int(seg.Addr) - origin + len(seg.Data); end > len(image) {
+			image = append(image, make([]byte, end-len(image))...)
+		}
+		copy(image[int(seg.Addr)-origin:], seg.Data)
+	}
+	return image
+}
+
+// Error is a mistake in the source.
+type Error struct {
+	Line int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %v: %v", e.Line, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Option configures [Assemble].
+type Option func(*assembler)
+
+// WithVariant assembles the opcodes of v instead of the 2A03, e.g. the 65C02's BRA and STZ.
+func WithVariant(v cpu.Variant) Option {
+	return func(a *assembler) {
+		a.variant = v
+	}
+}
+
+// Assemble assembles src. The error is an [*Error] with the line of the first mistake.
+func Assemble(src string, opts ...Option) (*Program, error) {
+	a := &assembler{symbols: map[string]int{}, picked: map[int]int{}}
+	for _, opt := range opts {
+		opt(a)
+	}
+	a.initializeOpcodes()
+
+	lines := strings.Split(src, "\n")
+	for a.pass = 1; a.pass <= 2; a.pass++ {
+		a.pc, a.scope, a.prog = 0, "", &Program{Symbols: map[string]uint16{}}
+		for i, line := range lines {
+			a.line = i + 1
+			if err := a.statement(line); err != nil {
+				return nil, &Error{Line: a.line, Err: err}
+			}
+		}
+	}
+
+	for name, val := range a.symbols {
+		a.prog.Symbols[name] = uint16(val)
+	}
+	if len(a.prog.Segments) > 0 && !a.wrote(0xFFFC) && !a.wrote(0xFFFD) {
+		start := a.prog.Segments[0].Addr
+		a.prog.Reset = &start
+	}
+	return a.prog, nil
+}
+
+// MustAssemble is [Assemble] for source that's known to be right, like test fixtures. It panics if there's a mistake.
+func MustAssemble(src string, opts ...Option) *Program {
+	prog, err := Assemble(src, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return prog
+}
+
+// assembler holds the state of a pass.
+type assembler struct {
+	variant cpu.Variant
+	// opcodes is the opcode of each mnemonic in each mode. It prefers the legal opcode when an illegal one does the same.
+	opcodes map[string]map[int]byte
+	symbols map[string]int
+	// picked is the mode the first pass picked for the instruction on each line, so the second pass makes it the same size.
+	picked map[int]int
+
+	pass  int
+	line  int
+	pc    int
+	scope string // the last label, which local labels belong to
+	prog  *Program
+}
+
+func (a *assembler) initializeOpcodes() {
+	a.opcodes = map[string]map[int]byte{}
+	ops := cpu.Opcodes(a.variant)
+	for _, op := range ops {
+		mode := modes[op.Mode]
+		byMode, ok := a.opcodes[op.Name]
+		if !ok {
+			byMode = map[int]byte{}
+			a.opcodes[op.Name] = byMode
+		}
+		if prev, ok := byMode[mode]; !ok || ops[prev].Illegal && !op.Illegal {
+			byMode[mode] = op.Opcode
+		}
+	}
+}
+
+// statement assembles one line.
+func (a *assembler) statement(line string) error {
+	text := strings.TrimSpace(stripComment(line))
+
+	if name, rest, ok := cutIdent(text, ':'); ok {
+		if err := a.define(name, a.pc, true); err != nil {
+			return err
+		}
+		text = rest
+	}
+	if name, rest, ok := cutIdent(text, '='); ok {
+		val, known, err := a.eval(rest)
+		if err != nil {
+			return err
+		}
+		if known {
+			return a.define(name, val, false)
+		}
+		return nil
+	}
+	if text == "" {
+		return nil
+	}
+
+	word, operand := text, ""
+	if i := strings.IndexAny(text, " \t"); i >= 0 {
+		word, operand = text[:i], strings.TrimSpace(text[i:])
+	}
+	if strings.HasPrefix(word, ".") {
+		return a.directive(strings.ToLower(word), operand)
+	}
+	return a.instruction(strings.ToUpper(word), operand)
+}
+
+// define sets a symbol. A label also starts the scope of the local labels after it.
+func (a *assembler) define(name string, val int, label bool) error {
+	if label && name[0] != '@' {
+		a.scope = name
+	}
+	name, err := a.qualify(name)
+	if err != nil {
+		return err
+	}
+	if prev, ok := a.symbols[name]; ok && a.pass == 1 {
+		return fmt.Errorf("%v is already defined", name)
+	} else if ok && label && prev != val {
+		return fmt.Errorf("%v moved from $%04X to $%04X between passes", name, prev, val)
+	}
+	a.symbols[name] = val
+	return nil
+}
+
+// qualify gives the full name of a symbol, which for a local label has the label it belongs to in front.
+func (a *assembler) qualify(name string) (string, error) {
+	if name[0] != '@' {
+		return name, nil
+	}
+	if a.scope == "" {
+		return "", fmt.Errorf("local label %v has no label before it", name)
+	}
+	return a.scope + name, nil
+}
+
+// emit writes bytes at the pc and moves past them.
+// It's a mistake to write over bytes that are already there, e.g. after an .org that goes back.
+func (a *assembler) emit(bytes ...byte) error {
+	if a.pc+len(bytes) > 0xFFFF+1 {
+		return fmt.Errorf("runs past the end of memory")
+	}
+	segs := a.prog.Segments
+	for _, seg := range segs {
+		if end := int(seg.Addr) + len(seg.Data); a.pc < end && int(seg.Addr) < a.pc+len(bytes) {
+			return fmt.Errorf("overlapping segment: $%04X-$%04X already has bytes", seg.Addr, end-1)
+		}
+	}
+	if n := len(segs); n > 0 && int(segs[n-1].Addr)+len(segs[n-1].Data) == a.pc {
+		segs[n-1].Data = append(segs[n-1].Data, bytes...)
+	} else {
+		a.prog.Segments = append(segs, cpu.Segment{Addr: uint16(a.pc), Data: append([]byte(nil), bytes...)})
+	}
+	a.pc += len(bytes)
+	return nil
+}
+
+// wrote reports whether the program has a byte at addr.
+func (a *assembler) wrote(addr uint16) bool {
+	for _, seg := range a.prog.Segments {
+		if seg.Addr <= addr && int(addr) < int(seg.Addr)+len(seg.Data) {
+			return true
+		}
+	}
+	return false
+}
+
+// directive assembles .org, .byte, .word and .res.
+func (a *assembler) directive(name, operand string) error {
+	args := splitTop(operand)
+	switch name {
+	case ".org":
+		if len(args) != 1 {
+			return fmt.Errorf(".org takes an address")
+		}
+		addr, err := a.evalNow(args[0])
+		if err != nil {
+			return err
+		}
+		if err := checkRange(addr, 0, 0xFFFF); err != nil {
+			return err
+		}
+		a.pc = addr
+		return nil
+
+	case ".byte":
+		for _, arg := range args {
+			if strings.HasPrefix(arg, `"`) {
+				str, err := strconv.Unquote(arg)
+				if err != nil {
+					return fmt.Errorf("bad string %v", arg)
+				}
+				if err := a.emit([]byte(str)...); err != nil {
+					return err
+				}
+				continue
+			}
+			val, err := a.evalByte(arg, -128)
+			if err != nil {
+				return err
+			}
+			if err := a.emit(val); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case ".word":
+		for _, arg := range args {
+			val, err := a.evalWord(arg)
+			if err != nil {
+				return err
+			}
+			if err := a.emit(byte(val), byte(val>>8)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case ".res":
+		if len(args) < 1 || len(args) > 2 {
+			return fmt.Errorf(".res takes a count and an optional fill byte")
+		}
+		n, err := a.evalNow(args[0])
+		if err != nil {
+			return err
+		}
+		if err := checkRange(n, 0, 0x10000); err != nil {
+			return err
+		}
+		fill := byte(0)
+		if len(args) == 2 {
+			if fill, err = a.evalByte(args[1], -128); err != nil {
+				return err
+			}
+		}
+		return a.emit([]byte(strings.Repeat(string([]byte{fill}), n))...)
+	}
+	return fmt.Errorf("unknown directive %v", name)
+}
+
+// evalNow evaluates an expression that has to be known in the first pass because it decides where things go.
+func (a *assembler) evalNow(s string) (int, error) {
+	val, known, err := a.eval(s)
+	if err == nil && !known {
+		err = fmt.Errorf("%v has to be defined before it's used here", s)
+	}
+	return val, err
+}
+
+// evalByte evaluates a byte, which can be negative down to lo.
+func (a *assembler) evalByte(s string, lo int) (byte, error) {
+	val, known, err := a.eval(s)
+	if err != nil {
+		return 0, err
+	}
+	if known {
+		if err := checkRange(val, lo, 0xFF); err != nil {
+			return 0, err
+		}
+	}
+	return byte(val), nil
+}
+
+// evalWord evaluates an address.
+func (a *assembler) evalWord(s string) (uint16, error) {
+	val, known, err := a.eval(s)
+	if err != nil {
+		return 0, err
+	}
+	if known {
+		if err := checkRange(val, 0, 0xFFFF); err != nil {
+			return 0, err
+		}
+	}
+	return uint16(val), nil
+}
+
+func checkRange(val, lo, hi int) error {
+	if val < lo || val > hi {
+		return fmt.Errorf("%v is out of range", val)
+	}
+	return nil
+}
+
+// stripComment cuts the comment off a line, minding semicolons in strings and characters.
+func stripComment(line string) string {
+	quote := byte(0)
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"':
+			quote = c
+		case c == '\'' && i+2 < len(line) && line[i+2] == '\'':
+			i += 2
+		case c == ';':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// cutIdent cuts a symbol followed by sep off the front of text, for "label:" and "NAME = expr".
+func cutIdent(text string, sep byte) (name, rest string, ok bool) {
+	if text == "" || !isIdentStart(text[0]) {
+		return "", "", false
+	}
+	i := 1
+	for i < len(text) && isIdentPart(text[i]) {
+		i++
+	}
+	j := i
+	for j < len(text) && (text[j] == ' ' || text[j] == '\t') {
+		j++
+	}
+	if j == len(text) || text[j] != sep {
+		return "", "", false
+	}
+	return text[:i], strings.TrimSpace(text[j+1:]), true
+}
+
+// splitTop splits on the commas that aren't in parentheses, strings or characters, and trims the parts.
+func splitTop(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	parts, depth, start, quote := []string{}, 0, 0, byte(0)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"':
+			quote = c
+		case c == '\'' && i+2 < len(s) && s[i+2] == '\'':
+			i += 2
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
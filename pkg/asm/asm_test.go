@@ -0,0 +1,160 @@
+package asm
+
+import (
+	"errors"
+	"testing"
+
+	"nes/pkg/cpu"
+	"nes/pkg/disasm"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAssemble(t *testing.T) {
+	Convey("assembles", t, func() {
+		bytes := func(src string, opts ...Option) []byte {
+			prog, err := Assemble(src, opts...)
+			So(err, ShouldBeNil)
+			return prog.Bytes()
+		}
+
+		Convey("every addressing mode", func() {
+			So(bytes("NOP"), ShouldResemble, []byte{0xea})
+			So(bytes("ASL"), ShouldResemble, []byte{0x0a})
+			So(bytes("asl a"), ShouldResemble, []byte{0x0a})
+			So(bytes("LDA #$20"), ShouldResemble, []byte{0xa9, 0x20})
+			So(bytes("LDA #-1"), ShouldResemble, []byte{0xa9, 0xff})
+			So(bytes("LDA $20"), ShouldResemble, []byte{0xa5, 0x20})
+			So(bytes("LDA $20,X"), ShouldResemble, []byte{0xb5, 0x20})
+			So(bytes("LDX $20, y"), ShouldResemble, []byte{0xb6, 0x20})
+			So(bytes("LDA $1234"), ShouldResemble, []byte{0xad, 0x34, 0x12})
+			So(bytes("LDA $0020"), ShouldResemble, []byte{0xa5, 0x20}) // it's the value that counts
+			So(bytes("LDA $1234,X"), ShouldResemble, []byte{0xbd, 0x34, 0x12})
+			So(bytes("LDA $20,Y"), ShouldResemble, []byte{0xb9, 0x20, 0x00}) // LDA has no zp,Y
+			So(bytes("JMP ($FFFC)"), ShouldResemble, []byte{0x6c, 0xfc, 0xff})
+			So(bytes("LDA ($20,X)"), ShouldResemble, []byte{0xa1, 0x20})
+			So(bytes("LDA ($20),Y"), ShouldResemble, []byte{0xb1, 0x20})
+			So(bytes("BRK"), ShouldResemble, []byte{0x00, 0x00})
+			So(bytes("SBC #1"), ShouldResemble, []byte{0xe9, 0x01}) // not the illegal $EB
+			So(bytes("LAX $20"), ShouldResemble, []byte{0xa7, 0x20})
+			So(bytes("JSR ($1230+4)"), ShouldResemble, []byte{0x20, 0x34, 0x12}) // the parentheses only group
+		})
+
+		Convey("the 65C02's", func() {
+			c02 := WithVariant(cpu.Variant65C02)
+			So(bytes("LDA ($20)", c02), ShouldResemble, []byte{0xb2, 0x20})
+			So(bytes("JMP ($1234,X)", c02), ShouldResemble, []byte{0x7c, 0x34, 0x12})
+			So(bytes("INC A", c02), ShouldResemble, []byte{0x1a})
+			So(bytes("here: BBS7 $20,here", c02), ShouldResemble, []byte{0xff, 0x20, 0xfd})
+			So(bytes("STZ $1234", c02), ShouldResemble, []byte{0x9c, 0x34, 0x12})
+		})
+
+		Convey("branches", func() {
+			So(bytes("loop: DEX\nBNE loop"), ShouldResemble, []byte{0xca, 0xd0, 0xfd})
+			So(bytes("BEQ done\nNOP\ndone: RTS"), ShouldResemble, []byte{0xf0, 0x01, 0xea, 0x60})
+			So(bytes("BNE *"), ShouldResemble, []byte{0xd0, 0xfe})
+		})
+
+		Convey("labels and constants", func() {
+			prog := MustAssemble(`
+PTR = $20          ; zero page
+SCREEN = $2000
+	.org $C000
+start:
+	LDA (PTR),Y
+	STA SCREEN+1
+	JMP later      ; forward so absolute
+later:
+	LDA data       ; backward but not in zero page
+	RTS
+data: .byte 1`)
+			So(prog.Origin(), ShouldEqual, 0xC000)
+			So(*prog.Reset, ShouldEqual, 0xC000)
+			So(prog.Bytes(), ShouldResemble, []byte{
+				0xb1, 0x20,
+				0x8d, 0x01, 0x20,
+				0x4c, 0x08, 0xc0,
+				0xad, 0x0c, 0xc0,
+				0x60,
+				0x01,
+			})
+			So(prog.Symbols, ShouldResemble, map[string]uint16{
+				"PTR": 0x20, "SCREEN": 0x2000, "start": 0xC000, "later": 0xC008, "data": 0xC00C,
+			})
+		})
+
+		Convey("a forward reference to zero page is still absolute", func() {
+			So(bytes("LDA zp\nzp = $20"), ShouldResemble, []byte{0xad, 0x20, 0x00})
+		})
+
+		Convey("local labels", func() {
+			prog := MustAssemble(`
+first:
+@loop:	DEX
+	BNE @loop
+second:
+@loop:	DEY
+	BNE @loop
+	JMP first@loop`)
+			So(prog.Bytes(), ShouldResemble, []byte{0xca, 0xd0, 0xfd, 0x88, 0xd0, 0xfd, 0x4c, 0x00, 0x00})
+			So(prog.Symbols["second@loop"], ShouldEqual, 3)
+		})
+
+		Convey("directives", func() {
+			So(bytes(`.byte 1, $ff, -1, 'A', "hi;\n"`), ShouldResemble, []byte{1, 0xff, 0xff, 'A', 'h', 'i', ';', '\n'})
+			So(bytes(".word $1234, end\nend:"), ShouldResemble, []byte{0x34, 0x12, 0x04, 0x00})
+			So(bytes(".res 3\n.res 2, $ea"), ShouldResemble, []byte{0, 0, 0, 0xea, 0xea})
+
+			prog := MustAssemble(".org $10\n.byte 1\n.org $20\n.byte 2")
+			So(prog.Segments, ShouldHaveLength, 2)
+			So(prog.Origin(), ShouldEqual, 0x10)
+			So(prog.Bytes(), ShouldHaveLength, 0x11)
+			So(prog.Bytes()[0x10], ShouldEqual, 2)
+		})
+
+		Convey("a program with its own vectors keeps them", func() {
+			prog := MustAssemble(".org $8000\nreset: JMP reset\n.org $FFFA\n.word reset, reset, reset")
+			So(prog.Reset, ShouldBeNil)
+			So(prog.Segments[1].Data, ShouldResemble, []byte{0x00, 0x80, 0x00, 0x80, 0x00, 0x80})
+		})
+
+		Convey("expressions", func() {
+			So(bytes(".byte 1+2*3, (1+2)*3, 7/2, 7%2, %101, %101%2"), ShouldResemble, []byte{7, 9, 3, 1, 5, 1})
+			So(bytes(".byte <$1234, >$1234, ~0 & $0F, 1<<4 | 1, $F0>>4 ^ 1"), ShouldResemble, []byte{0x34, 0x12, 0x0f, 0x11, 0x0e})
+			So(bytes(".org $10\n.word *+2"), ShouldResemble, []byte{0x12, 0x00})
+			So(bytes(".byte 010, 0x10"), ShouldResemble, []byte{10, 0x10}) // decimal even with a leading 0
+			So(bytes("LDA #<msg\nLDX #>msg\n.org $1234\nmsg:"), ShouldResemble, []byte{0xa9, 0x34, 0xa2, 0x12})
+		})
+
+		Convey("round trips through the disassembler", func() {
+			d := disasm.New()
+			for _, src := range []string{"LDA ($20),Y", "JMP ($FFFC)", "STA $1234,X", "ROR A", "LDX $20,Y", "SLO ($20,X)", "CPY #$7F"} {
+				So(d.Decode(disasm.Bytes{Data: bytes(src)}, 0).String(), ShouldEqual, src)
+			}
+		})
+	})
+
+	Convey("reports mistakes with their line", t, func() {
+		mistake := func(src string) string {
+			_, err := Assemble(src)
+			So(err, ShouldNotBeNil)
+			var asmErr *Error
+			So(errors.As(err, &asmErr), ShouldBeTrue)
+			return err.Error()
+		}
+
+		So(mistake("NOP\nFOO"), ShouldEqual, "line 2: unknown instruction FOO")
+		So(mistake("JMP nowhere"), ShouldEqual, "line 1: undefined symbol nowhere")
+		So(mistake("a:\na:"), ShouldEqual, "line 2: a is already defined")
+		So(mistake("LDA #256"), ShouldEqual, "line 1: 256 is out of range")
+		So(mistake("STA #1"), ShouldEqual, "line 1: STA doesn't have that addressing mode")
+		So(mistake("LDA ($20)"), ShouldEqual, "line 1: LDA doesn't have that addressing mode") // only the 65C02 has (zp)
+		So(mistake("BNE far\n.res 200\nfar:"), ShouldEqual, "line 1: branch to $00CA is too far")
+		So(mistake("@loop: NOP"), ShouldEqual, "line 1: local label @loop has no label before it")
+		So(mistake(".org later\nlater:"), ShouldEqual, "line 1: later has to be defined before it's used here")
+		So(mistake(".byte 1/0"), ShouldEqual, "line 1: division by zero")
+		So(mistake(".org $FFFF\nNOP\nNOP"), ShouldEqual, "line 3: runs past the end of memory")
+		So(mistake(".bogus"), ShouldEqual, "line 1: unknown directive .bogus")
+		So(mistake(".org $10\n.byte 1, 2\n.org $11\n.byte 3"), ShouldEqual, "line 4: overlapping segment: $0010-$0011 already has bytes")
+	})
+}
@@ -0,0 +1,248 @@
+package asm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Expressions are C-like with the 6502 assemblers' extras:
+//
+//	$FF or 0xFF hex, %1010 binary, 255 decimal (a leading 0 doesn't make it octal), 'c' a character
+//	* the address of the current instruction
+//	<expr and >expr the low and high byte
+//	| ^ & << >> + - * / % from lowest to highest precedence, and unary - ~
+//
+// A symbol that isn't defined yet is unknown in the first pass, which is fine as long as the size of what uses it
+// doesn't depend on its value. In the second pass it's an error.
+
+// binaryPrecedence is the precedence of the binary operators, higher binds tighter.
+var binaryPrecedence = map[string]int{
+	"|": 1, "^": 2, "&": 3, "<<": 4, ">>": 4, "+": 5, "-": 5, "*": 6, "/": 6, "%": 6,
+}
+
+// parser evaluates one expression.
+type parser struct {
+	a      *assembler
+	tokens []string
+	pos    int
+	known  bool // false once a symbol that isn't defined yet is used
+}
+
+// eval evaluates s. known is false if it uses a symbol that isn't defined yet, which is only allowed in the first pass.
+func (a *assembler) eval(s string) (val int, known bool, err error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(tokens) == 0 {
+		return 0, false, fmt.Errorf("missing expression")
+	}
+	p := &parser{a: a, tokens: tokens, known: true}
+	val, err = p.binary(1)
+	if err != nil {
+		return 0, false, err
+	}
+	if p.pos < len(p.tokens) {
+		return 0, false, fmt.Errorf("unexpected %q in %q", p.tokens[p.pos], s)
+	}
+	return val, p.known, nil
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *parser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+// binary parses operators of at least precedence prec, by precedence climbing.
+func (p *parser) binary(prec int) (int, error) {
+	left, err := p.unary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		opPrec, ok := binaryPrecedence[op]
+		if !ok || opPrec < prec {
+			return left, nil
+		}
+		p.next()
+		right, err := p.binary(opPrec + 1)
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case "|":
+			left |= right
+		case "^":
+			left ^= right
+		case "&":
+			left &= right
+		case "<<":
+			left <<= right
+		case ">>":
+			left >>= right
+		case "+":
+			left += right
+		case "-":
+			left -= right
+		case "*":
+			left *= right
+		case "/", "%":
+			if right == 0 {
+				if !p.known {
+					return 0, nil // it might not be 0 once the symbol is defined
+				}
+				return 0, fmt.Errorf("division by zero")
+			}
+			if op == "/" {
+				left /= right
+			} else {
+				left %= right
+			}
+		}
+	}
+}
+
+func (p *parser) unary() (int, error) {
+	switch p.peek() {
+	case "-", "~", "<", ">":
+		op := p.next()
+		val, err := p.unary()
+		switch op {
+		case "-":
+			val = -val
+		case "~":
+			val = ^val
+		case "<":
+			val &= 0xFF
+		case ">":
+			val = val >> 8 & 0xFF
+		}
+		return val, err
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (int, error) {
+	tok := p.next()
+	switch {
+	case tok == "":
+		return 0, fmt.Errorf("expression ends early")
+	case tok == "(":
+		val, err := p.binary(1)
+		if err != nil {
+			return 0, err
+		}
+		if p.next() != ")" {
+			return 0, fmt.Errorf("missing )")
+		}
+		return val, nil
+	case tok == "*":
+		return p.a.pc, nil
+	case tok[0] == '$':
+		val, err := strconv.ParseUint(tok[1:], 16, 32)
+		return int(val), err
+	case tok[0] == '%':
+		val, err := strconv.ParseUint(tok[1:], 2, 32)
+		return int(val), err
+	case tok[0] == '\'':
+		return int(tok[1]), nil
+	case len(tok) > 2 && tok[0] == '0' && (tok[1] == 'x' || tok[1] == 'X'):
+		val, err := strconv.ParseUint(tok[2:], 16, 32)
+		return int(val), err
+	case isDigit(tok[0]):
+		val, err := strconv.ParseUint(tok, 10, 32)
+		return int(val), err
+	case isIdentStart(tok[0]):
+		name, err := p.a.qualify(tok)
+		if err != nil {
+			return 0, err
+		}
+		val, ok := p.a.symbols[name]
+		if !ok {
+			if p.a.pass == 2 {
+				return 0, fmt.Errorf("undefined symbol %v", tok)
+			}
+			p.known = false
+		}
+		return val, nil
+	}
+	return 0, fmt.Errorf("unexpected %q", tok)
+}
+
+// tokenize splits an expression into numbers, symbols, characters and operators.
+func tokenize(s string) ([]string, error) {
+	tokens := []string{}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '\'':
+			if i+2 >= len(s) || s[i+2] != '\'' {
+				return nil, fmt.Errorf("bad character in %q", s)
+			}
+			tokens = append(tokens, s[i:i+3])
+			i += 3
+		case c == '<' || c == '>':
+			if i+1 < len(s) && s[i+1] == c {
+				tokens = append(tokens, s[i:i+2])
+				i += 2
+			} else {
+				tokens = append(tokens, s[i:i+1])
+				i++
+			}
+		case c == '%' && !afterOperand(tokens):
+			j := i + 1
+			for j < len(s) && (s[j] == '0' || s[j] == '1') {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		case strings.IndexByte("+-*/%&|^~()", c) >= 0:
+			tokens = append(tokens, s[i:i+1])
+			i++
+		case c == '$' || isDigit(c) || isIdentStart(c):
+			j := i + 1
+			for j < len(s) && isIdentPart(s[j]) {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q in %q", c, s)
+		}
+	}
+	return tokens, nil
+}
+
+// afterOperand reports whether the last token ends an operand, so a % after it is modulo rather than a binary number.
+func afterOperand(tokens []string) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	last := tokens[len(tokens)-1]
+	return last == ")" || len(last) > 1 && last[0] == '%' || last[0] == '$' || last[0] == '\'' || isIdentPart(last[0])
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// isIdentStart reports whether c can start a symbol. Local labels start with @.
+func isIdentStart(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' || c == '@'
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
@@ -0,0 +1,233 @@
+package asm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The addressing modes, by the names in the cpu package's opcode table.
+const (
+	implicit = iota
+	accumulator
+	immediate
+	zeroPage
+	zeroPageX
+	zeroPageY
+	relative
+	absolute
+	absoluteX
+	absoluteY
+	indirect
+	indirectX
+	indirectY
+	zeroPageIndirect
+	absoluteIndirectX
+	zeroPageRelative
+)
+
+var modes = map[string]int{
+	"implicit": implicit, "accumulator": accumulator, "immediate": immediate,
+	"zeroPage": zeroPage, "zeroPageX": zeroPageX, "zeroPageY": zeroPageY, "relative": relative,
+	"absolute": absolute, "absoluteX": absoluteX, "absoluteY": absoluteY,
+	"indirect": indirect, "indirectX": indirectX, "indirectY": indirectY,
+	"zeroPageIndirect": zeroPageIndirect, "absoluteIndirectX": absoluteIndirectX, "zeroPageRelative": zeroPageRelative,
+}
+
+// The syntax of an operand decides which modes it can be. Where there's a zero page and an absolute mode the
+// zero page one comes first.
+var syntaxModes = map[string][]int{
+	"":      {implicit, accumulator}, // ASL
+	"A":     {accumulator},           // ASL A
+	"#":     {immediate},             // LDA #$20
+	"x":     {relative, zeroPage, absolute},
+	"x,X":   {zeroPageX, absoluteX},
+	"x,Y":   {zeroPageY, absoluteY},
+	"(x)":   {zeroPageIndirect, indirect},
+	"(x,X)": {indirectX, absoluteIndirectX},
+	"(x),Y": {indirectY},
+	"x,x":   {zeroPageRelative}, // BBR0 $20,target
+}
+
+// hasZeroPageOrIndirect reports whether an instruction has a mode that parentheses could have meant,
+// e.g. LDA ($20) is a mistake on the 6502, not LDA $20.
+func hasZeroPageOrIndirect(byMode map[int]byte) bool {
+	for mode := range byMode {
+		switch mode {
+		case zeroPage, zeroPageX, zeroPageY, indirect, indirectX, indirectY, zeroPageIndirect, absoluteIndirectX, zeroPageRelative:
+			return true
+		}
+	}
+	return false
+}
+
+// instruction assembles an instruction.
+func (a *assembler) instruction(name, operand string) error {
+	byMode, ok := a.opcodes[name]
+	if !ok {
+		return fmt.Errorf("unknown instruction %v", name)
+	}
+	syntax, exprs, err := parseOperand(operand)
+	if err != nil {
+		return err
+	}
+	candidates := []int{}
+	for _, mode := range syntaxModes[syntax] {
+		if _, ok := byMode[mode]; ok {
+			candidates = append(candidates, mode)
+		}
+	}
+	if len(candidates) == 0 && syntax == "(x)" && !hasZeroPageOrIndirect(byMode) { // the parentheses are just grouping, like JSR (base+2)
+		syntax, exprs = "x", []string{operand}
+		for _, mode := range syntaxModes[syntax] {
+			if _, ok := byMode[mode]; ok {
+				candidates = append(candidates, mode)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("%v doesn't have that addressing mode", name)
+	}
+
+	mode := candidates[0]
+	if len(candidates) > 1 && candidates[0] != relative {
+		if a.pass == 1 {
+			val, known, err := a.eval(exprs[0])
+			if err != nil {
+				return err
+			}
+			if !known || val < 0 || val > 0xFF {
+				mode = candidates[1]
+			}
+			a.picked[a.line] = mode
+		}
+		mode = a.picked[a.line]
+	}
+	return a.encode(byMode[mode], mode, exprs)
+}
+
+// encode writes the opcode and its operand.
+func (a *assembler) encode(opcode byte, mode int, exprs []string) error {
+	start := a.pc
+	switch mode {
+	case implicit, accumulator:
+		if err := a.emit(opcode); err != nil {
+			return err
+		}
+		if opcode == 0x00 { // BRK skips the byte after it
+			return a.emit(0)
+		}
+		return nil
+
+	case immediate:
+		val, err := a.evalByte(exprs[0], -128)
+		if err != nil {
+			return err
+		}
+		return a.emit(opcode, val)
+
+	case zeroPage, zeroPageX, zeroPageY, indirectX, indirectY, zeroPageIndirect:
+		val, err := a.evalByte(exprs[0], 0)
+		if err != nil {
+			return err
+		}
+		return a.emit(opcode, val)
+
+	case absolute, absoluteX, absoluteY, indirect, absoluteIndirectX:
+		val, err := a.evalWord(exprs[0])
+		if err != nil {
+			return err
+		}
+		return a.emit(opcode, byte(val), byte(val>>8))
+
+	case relative:
+		offset, err := a.branch(exprs[0], start+2)
+		if err != nil {
+			return err
+		}
+		return a.emit(opcode, offset)
+
+	case zeroPageRelative:
+		zp, err := a.evalByte(exprs[0], 0)
+		if err != nil {
+			return err
+		}
+		offset, err := a.branch(exprs[1], start+3)
+		if err != nil {
+			return err
+		}
+		return a.emit(opcode, zp, offset)
+	}
+	return fmt.Errorf("unknown mode %v", mode)
+}
+
+// branch evaluates a branch target as an offset from next, the address after the branch.
+func (a *assembler) branch(s string, next int) (byte, error) {
+	target, known, err := a.eval(s)
+	if err != nil || !known {
+		return 0, err
+	}
+	offset := target - next
+	if offset < -128 || offset > 127 {
+		return 0, fmt.Errorf("branch to $%04X is too far", target)
+	}
+	return byte(offset), nil
+}
+
+// parseOperand works out the syntax of an operand, see [syntaxModes], and the expressions in it.
+func parseOperand(s string) (syntax string, exprs []string, err error) {
+	switch {
+	case s == "":
+		return "", nil, nil
+	case strings.EqualFold(s, "A"):
+		return "A", nil, nil
+	case s[0] == '#':
+		return "#", []string{s[1:]}, nil
+	}
+
+	parts := splitTop(s)
+	switch len(parts) {
+	case 1:
+		if wrapped(s) {
+			inner := splitTop(s[1 : len(s)-1])
+			if len(inner) == 2 && strings.EqualFold(inner[1], "X") {
+				return "(x,X)", inner[:1], nil
+			}
+			if len(inner) == 1 {
+				return "(x)", inner, nil
+			}
+			return "", nil, fmt.Errorf("bad operand %v", s)
+		}
+		return "x", parts, nil
+	case 2:
+		switch {
+		case strings.EqualFold(parts[1], "Y") && wrapped(parts[0]):
+			return "(x),Y", []string{parts[0][1 : len(parts[0])-1]}, nil
+		case strings.EqualFold(parts[1], "X"):
+			return "x,X", parts[:1], nil
+		case strings.EqualFold(parts[1], "Y"):
+			return "x,Y", parts[:1], nil
+		}
+		return "x,x", parts, nil
+	}
+	return "", nil, fmt.Errorf("bad operand %v", s)
+}
+
+// wrapped reports whether s is all in one pair of parentheses, unlike ($20),Y or (1+2)*3.
+func wrapped(s string) bool {
+	if len(s) < 2 || s[0] != '(' || s[len(s)-1] != ')' {
+		return false
+	}
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 && i < len(s)-1 {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+// Package bus is the NES CPU's memory map, which plugs into the CPU as its Bus.
+//
+//	0x0000-0x07FF  2KiB internal RAM
+//	0x0800-0x1FFF  mirrors of the RAM
+//	0x2000-0x2007  PPU registers
+//	0x2008-0x3FFF  mirrors of the PPU registers every 8 bytes
+//	0x4000-0x401F  APU and I/O registers
+//	0x4020-0xFFFF  cartridge space
+//
+// https://www.nesdev.org/wiki/CPU_memory_map
+package bus
+
+const (
+	ramSize   = 0x0800
+	ramEnd    = 0x2000 // the end of RAM and its mirrors
+	ppuBase   = 0x2000
+	ppuEnd    = 0x4000 // the end of the PPU registers and their mirrors
+	ioEnd     = 0x4020 // the end of the APU and I/O registers
+	ppuMirror = 0x0007 // the PPU's 8 registers repeat every 8 bytes
+)
+
+// Device is something mapped into the NES memory map, like the PPU, the APU or a cartridge.
+//
+// Read returns ok false if the device doesn't drive the data bus for addr, so the CPU reads the open bus value instead.
+type Device interface {
+	Read(addr uint16) (dat byte, ok bool)
+	Write(addr uint16, dat byte)
+}
+
+// Peeker is implemented by a [Device] that can be read without side effects, e.g. without clearing the PPU's vblank flag.
+type Peeker interface {
+	Peek(addr uint16) (dat byte, ok bool)
+}
+
+// NES is the NES CPU's memory map. The zero value has nothing but RAM attached.
+//
+// Anything without a device attached, or whose device doesn't drive the data bus, reads as open bus:
+// the last byte that was on the data bus, since nothing pulls the lines to a new value.
+// https://www.nesdev.org/wiki/Open_bus_behavior
+type NES struct {
+	ram [ramSize]byte
+	// PPU gets the registers at 0x2000-0x3FFF with the address mirrored down to 0x2000-0x2007.
+	PPU Device
+	// IO gets the APU and I/O registers at 0x4000-0x401F, including the controllers and OAM DMA.
+	IO Device
+	// Cart gets the cartridge space at 0x4020-0xFFFF.
+	Cart Device
+	// openBus is the last byte on the data bus.
+	openBus byte
+}
+
+// OpenBus returns the value left on the data bus by the last read or write.
+// Devices that only drive some of the data lines, like the controller ports, can use it for the rest.
+func (bus *NES) OpenBus() byte {
+	return bus.openBus
+}
+
+// device finds what's mapped at addr outside of RAM and the address to give it.
+func (bus *NES) device(addr uint16) (Device, uint16) {
+	switch {
+	case addr < ppuEnd:
+		return bus.PPU, ppuBase | addr&ppuMirror
+	case addr < ioEnd:
+		return bus.IO, addr
+	default:
+		return bus.Cart, addr
+	}
+}
+
+func (bus *NES) Read(addr uint16) byte {
+	if addr < ramEnd {
+		bus.openBus = bus.ram[addr%ramSize]
+		return bus.openBus
+	}
+	if dev, addr := bus.device(addr); dev != nil {
+		if dat, ok := dev.Read(addr); ok {
+			bus.openBus = dat
+		}
+	}
+	return bus.openBus
+}
+
+func (bus *NES) Write(addr uint16, dat byte) {
+	bus.openBus = dat
+	if addr < ramEnd {
+		bus.ram[addr%ramSize] = dat
+		return
+	}
+	if dev, addr := bus.device(addr); dev != nil {
+		dev.Write(addr, dat)
+	}
+}
+
+// Peek reads addr like [NES.Read] but without side effects on the devices or the open bus value.
+// A device that isn't a [Peeker] can't be read safely so it peeks as open bus.
+func (bus *NES) Peek(addr uint16) byte {
+	if addr < ramEnd {
+		return bus.ram[addr%ramSize]
+	}
+	if dev, addr := bus.device(addr); dev != nil {
+		if p, ok := dev.(Peeker); ok {
+			if dat, ok := p.Peek(addr); ok {
+				return dat
+			}
+		}
+	}
+	return bus.openBus
+}
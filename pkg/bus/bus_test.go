@@ -0,0 +1,111 @@
+package bus
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// regs is a device that records the addresses it's given and drives the bus for addresses below drives.
+type regs struct {
+	mem    map[uint16]byte
+	drives uint16
+	reads  []uint16
+}
+
+func newRegs(drives uint16) *regs {
+	return &regs{mem: map[uint16]byte{}, drives: drives}
+}
+
+func (r *regs) Read(addr uint16) (byte, bool) {
+	r.reads = append(r.reads, addr)
+	return r.mem[addr], addr < r.drives
+}
+
+func (r *regs) Write(addr uint16, dat byte) {
+	r.mem[addr] = dat
+}
+
+// peekRegs is regs that can be peeked.
+type peekRegs struct {
+	*regs
+}
+
+func (r peekRegs) Peek(addr uint16) (byte, bool) {
+	return r.mem[addr], addr < r.drives
+}
+
+func TestNES(t *testing.T) {
+	Convey("should map the NES CPU address space", t, func() {
+		bus := &NES{}
+
+		Convey("ram is mirrored through 0x1FFF", func() {
+			bus.Write(0x0012, 0x34)
+			for _, mirror := range []uint16{0x0012, 0x0812, 0x1012, 0x1812} {
+				So(bus.Read(mirror), ShouldEqual, 0x34)
+			}
+			bus.Write(0x1FFF, 0x56)
+			So(bus.Read(0x07FF), ShouldEqual, 0x56)
+		})
+
+		Convey("ppu registers are mirrored every 8 bytes through 0x3FFF", func() {
+			ppu := newRegs(0xFFFF)
+			bus.PPU = ppu
+			bus.Write(0x3FFE, 0x78)
+			So(ppu.mem[0x2006], ShouldEqual, 0x78)
+			So(bus.Read(0x200E), ShouldEqual, 0x78)
+			bus.Read(0x2002)
+			bus.Read(0x3FFA)
+			So(ppu.reads, ShouldResemble, []uint16{0x2006, 0x2002, 0x2002})
+		})
+
+		Convey("io and cartridge get their addresses as is", func() {
+			io, cart := newRegs(0xFFFF), newRegs(0xFFFF)
+			bus.IO, bus.Cart = io, cart
+			bus.Write(0x4016, 0x01)
+			bus.Write(0x401F, 0x02)
+			bus.Write(0x4020, 0x03)
+			bus.Write(0xFFFC, 0x04)
+			So(io.mem, ShouldResemble, map[uint16]byte{0x4016: 0x01, 0x401F: 0x02})
+			So(cart.mem, ShouldResemble, map[uint16]byte{0x4020: 0x03, 0xFFFC: 0x04})
+		})
+
+		Convey("unmapped reads return the open bus value", func() {
+			bus.Write(0x0000, 0x40)
+			bus.Write(0x0001, 0x41)
+			bus.Read(0x0000)
+			So(bus.Read(0x5000), ShouldEqual, 0x40)
+
+			bus.Write(0x4000, 0x99) // writes drive the bus too, even with nothing there
+			So(bus.Read(0x8000), ShouldEqual, 0x99)
+			So(bus.OpenBus(), ShouldEqual, 0x99)
+		})
+
+		Convey("a device that doesn't drive the bus reads as open bus", func() {
+			io := newRegs(0x4016) // only the APU drives the bus
+			io.mem[0x4017] = 0x01
+			bus.IO = io
+			bus.Write(0x0010, 0x41)
+			bus.Read(0x0010)
+			So(bus.Read(0x4017), ShouldEqual, 0x41)
+			So(io.reads, ShouldResemble, []uint16{0x4017})
+		})
+
+		Convey("peek has no side effects", func() {
+			ppu := newRegs(0xFFFF)
+			ppu.mem[0x2002] = 0x80
+			bus.PPU = ppu
+			bus.Write(0x0700, 0x11)
+			bus.Read(0x0700)
+
+			So(bus.Peek(0x0F00), ShouldEqual, 0x11)
+			So(bus.Peek(0x2002), ShouldEqual, 0x11) // the ppu can't be peeked
+			So(ppu.reads, ShouldBeEmpty)
+
+			bus.PPU = peekRegs{ppu}
+			So(bus.Peek(0x200A), ShouldEqual, 0x80)
+			So(ppu.reads, ShouldBeEmpty)
+			So(bus.OpenBus(), ShouldEqual, 0x11)
+		})
+	})
+}
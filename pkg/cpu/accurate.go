@@ -0,0 +1,93 @@
+package cpu
+
+// The accurate executor performs every bus read and write on the cycle the real 6502 does, including the dummy reads and writes.
+// Every cycle of the 6502 is exactly one bus access so the cycle count falls out of counting them in [CPU.tick].
+//
+// It runs the same handlers from [CPU.opcodes] as [CPU.step] and only adds the accesses the handlers don't do themselves.
+// The dummy reads of the addressing modes are done by [CPU.address].
+// See https://www.nesdev.org/6502_cpu.txt for the cycle by cycle breakdown of every addressing mode.
+
+// tick counts one bus cycle of the accurate executor.
+//
+// The interrupt lines are sampled at the end of every cycle, see [CPU.polled].
+func (cpu *CPU) tick(addr uint16, dat byte, write bool) {
+	cpu.cycles += 1
+	if cpu.onCycle != nil {
+		cpu.onCycle(addr, dat, write)
+	}
+	cpu.pollPrev, cpu.pollNow = cpu.pollNow, cpu.nmiPending || cpu.irqLine && !cpu.status.Flag(posI)
+}
+
+// stack and control flow opcodes with their own bus patterns
+const (
+	opBRK = 0x00
+	opJSR = 0x20
+	opRTI = 0x40
+	opRTS = 0x60
+	opPLP = 0x28
+	opPLA = 0x68
+)
+
+// stepAccurate is the bus-cycle-accurate version of [CPU.step].
+// It executes the instruction at [CPU.pc], or the interrupt sequence if an interrupt was polled, and returns what it ran.
+func (cpu *CPU) stepAccurate() (StepInfo, error) {
+	if cpu.polled {
+		cpu.polled = false
+		return cpu.interrupt(), nil
+	}
+	start := cpu.cycles
+	if cpu.trace != nil {
+		cpu.traceInstruction()
+	}
+
+	opc := cpu.read(cpu.pc)
+	op, nPC := &cpu.opcodes[opc], cpu.pc+1
+	info := StepInfo{PC: cpu.pc, Opcode: opc, Name: op.Name}
+	if op.Illegal && cpu.strict {
+		// the opcode fetch already happened on the bus, same as the real CPU would before jamming on it
+		info.Cycles = int(cpu.cycles - start)
+		return info, &IllegalOpcodeError{PC: cpu.pc, Opcode: opc, Name: op.Name}
+	}
+	if opc == opJSR {
+		info.Addr = cpu.jsrAccurate(nPC)
+		info.Cycles = int(cpu.cycles - start)
+		return info, nil
+	}
+	dat, _ := cpu.address(op, nPC)
+	switch opc {
+	case opPLP, opPLA, opRTI, opRTS:
+		cpu.read(cpu.effStack()) // reads the top of the stack while it increments S
+	}
+	cpu.pc += op.Size
+	dat.pc = cpu.pc
+
+	cpu.extraCycles = 0
+	op.Do(dat)
+
+	switch {
+	case op.Mode == relative && cpu.extraCycles > 0:
+		cpu.read(dat.pc) // a taken branch reads the next opcode while it adds the offset
+		if cpu.extraCycles > 1 {
+			cpu.read(dat.pc&0xFF00 | dat.addr&0x00FF) // and then the target before fixing the page
+		}
+	case opc == opRTS:
+		cpu.read(cpu.pc - 1) // reads the pulled address while it increments it
+	}
+
+	cpu.polled = cpu.pollPrev && !cpu.interrupted
+	cpu.interrupted = false
+	info.Addr = dat.addr
+	info.Cycles = int(cpu.cycles - start)
+	return info, nil
+}
+
+// jsrAccurate runs JSR, which has to push the return address in between fetching the two bytes of the target, and returns the target.
+func (cpu *CPU) jsrAccurate(nPC uint16) uint16 {
+	lo := cpu.read(nPC)
+	cpu.read(cpu.effStack()) // internal operation, reads the stack
+	cpu.push16(nPC + 1)      // the last byte of the JSR, same as [CPU.jsr]
+	hi := cpu.read(nPC + 1)
+	cpu.pc = uint16(hi)<<8 | uint16(lo)
+	cpu.polled = cpu.pollPrev
+	return cpu.pc
+}
@@ -0,0 +1,180 @@
+package cpu
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type busCycle struct {
+	addr  uint16
+	dat   byte
+	write bool
+}
+
+func (c busCycle) String() string {
+	rw := "read"
+	if c.write {
+		rw = "write"
+	}
+	return fmt.Sprintf("%04x %02x %v", c.addr, c.dat, rw)
+}
+
+func r(addr uint16, dat byte) busCycle { return busCycle{addr, dat, false} }
+func w(addr uint16, dat byte) busCycle { return busCycle{addr, dat, true} }
+
+func TestAccurate(t *testing.T) {
+	Convey("should do every bus access on its cycle", t, func() {
+		cpu := newCPU()
+		cpu.s = 0xfd
+
+		var log []busCycle
+		cpu.onCycle = func(addr uint16, dat byte, write bool) {
+			log = append(log, busCycle{addr, dat, write})
+		}
+
+		run := func(program ...byte) []busCycle {
+			copy(cpu.ram()[0x0200:], program)
+			cpu.pc = 0x0200
+			log = nil
+			cpu.accurate = true
+			cycles := stepCycles(cpu.Step())
+			cpu.accurate = false
+			So(cycles, ShouldEqual, len(log))
+			return log
+		}
+
+		Convey("implied reads the next byte", func() {
+			So(run(0xe8, 0x55), ShouldResemble, []busCycle{r(0x0200, 0xe8), r(0x0201, 0x55)})
+		})
+
+		Convey("immediate", func() {
+			So(run(0xa9, 0x42), ShouldResemble, []busCycle{r(0x0200, 0xa9), r(0x0201, 0x42)})
+		})
+
+		Convey("zero page,x reads the unindexed address", func() {
+			cpu.x = 0x05
+			cpu.ram()[0x0040], cpu.ram()[0x0045] = 0x11, 0x22
+			So(run(0xb5, 0x40), ShouldResemble, []busCycle{
+				r(0x0200, 0xb5), r(0x0201, 0x40), r(0x0040, 0x11), r(0x0045, 0x22),
+			})
+		})
+
+		Convey("absolute,x read only reads the wrong page when it crosses", func() {
+			cpu.x = 0x20
+			cpu.ram()[0x0320], cpu.ram()[0x0310], cpu.ram()[0x0410] = 0x11, 0x22, 0x33
+			So(run(0xbd, 0x00, 0x03), ShouldResemble, []busCycle{
+				r(0x0200, 0xbd), r(0x0201, 0x00), r(0x0202, 0x03), r(0x0320, 0x11),
+			})
+			So(run(0xbd, 0xf0, 0x03), ShouldResemble, []busCycle{
+				r(0x0200, 0xbd), r(0x0201, 0xf0), r(0x0202, 0x03), r(0x0310, 0x22), r(0x0410, 0x33),
+			})
+		})
+
+		Convey("absolute,x store always reads before it writes", func() {
+			cpu.x, cpu.a = 0x20, 0x99
+			cpu.ram()[0x0320] = 0x11
+			So(run(0x9d, 0x00, 0x03), ShouldResemble, []busCycle{
+				r(0x0200, 0x9d), r(0x0201, 0x00), r(0x0202, 0x03), r(0x0320, 0x11), w(0x0320, 0x99),
+			})
+		})
+
+		Convey("read-modify-write writes twice", func() {
+			cpu.ram()[0x0040] = 0x41
+			So(run(0xe6, 0x40), ShouldResemble, []busCycle{
+				r(0x0200, 0xe6), r(0x0201, 0x40), r(0x0040, 0x41), w(0x0040, 0x41), w(0x0040, 0x42),
+			})
+		})
+
+		Convey("(indirect),y", func() {
+			cpu.y = 0x10
+			cpu.ram()[0x0040], cpu.ram()[0x0041] = 0xf8, 0x03
+			cpu.ram()[0x0308], cpu.ram()[0x0408] = 0x11, 0x22
+			So(run(0xb1, 0x40), ShouldResemble, []busCycle{
+				r(0x0200, 0xb1), r(0x0201, 0x40), r(0x0040, 0xf8), r(0x0041, 0x03), r(0x0308, 0x11), r(0x0408, 0x22),
+			})
+		})
+
+		Convey("(indirect,x) reads the pointer before indexing it", func() {
+			cpu.x = 0x02
+			cpu.ram()[0x0040] = 0x77
+			cpu.ram()[0x0042], cpu.ram()[0x0043] = 0x00, 0x03
+			cpu.ram()[0x0300] = 0x11
+			So(run(0xa1, 0x40), ShouldResemble, []busCycle{
+				r(0x0200, 0xa1), r(0x0201, 0x40), r(0x0040, 0x77), r(0x0042, 0x00), r(0x0043, 0x03), r(0x0300, 0x11),
+			})
+		})
+
+		Convey("stack ops", func() {
+			cpu.a = 0x99
+			cpu.ram()[0x01fd] = 0x11
+			So(run(0x48, 0x55), ShouldResemble, []busCycle{r(0x0200, 0x48), r(0x0201, 0x55), w(0x01fd, 0x99)})
+			So(run(0x68, 0x55), ShouldResemble, []busCycle{r(0x0200, 0x68), r(0x0201, 0x55), r(0x01fc, 0x00), r(0x01fd, 0x99)})
+		})
+
+		Convey("jsr pushes between the operand bytes and rts reads the return address", func() {
+			So(run(0x20, 0x34, 0x12), ShouldResemble, []busCycle{
+				r(0x0200, 0x20), r(0x0201, 0x34), r(0x01fd, 0x00), w(0x01fd, 0x02), w(0x01fc, 0x02), r(0x0202, 0x12),
+			})
+			So(cpu.pc, ShouldEqual, 0x1234)
+
+			So(run(0x60, 0x55), ShouldResemble, []busCycle{
+				r(0x0200, 0x60), r(0x0201, 0x55), r(0x01fb, 0x00), r(0x01fc, 0x02), r(0x01fd, 0x02), r(0x0202, 0x12),
+			})
+			So(cpu.pc, ShouldEqual, 0x0203)
+		})
+
+		Convey("brk", func() {
+			cpu.ram()[0xfffe], cpu.ram()[0xffff] = 0x34, 0x12
+			So(run(0x00, 0x55), ShouldResemble, []busCycle{
+				r(0x0200, 0x00), r(0x0201, 0x55), w(0x01fd, 0x02), w(0x01fc, 0x02), w(0x01fb, posB|pos_), r(0xfffe, 0x34), r(0xffff, 0x12),
+			})
+		})
+
+		Convey("taken branches read the next opcode and the unfixed target", func() {
+			cpu.status.SetFlag(posZ, true)
+			cpu.ram()[0x0202] = 0xea
+			So(run(0xf0, 0x02), ShouldResemble, []busCycle{r(0x0200, 0xf0), r(0x0201, 0x02), r(0x0202, 0xea)})
+			So(run(0xf0, 0x80), ShouldResemble, []busCycle{r(0x0200, 0xf0), r(0x0201, 0x80), r(0x0202, 0xea), r(0x0282, 0x00)})
+			So(cpu.pc, ShouldEqual, 0x0182)
+		})
+
+		Convey("jmp indirect", func() {
+			cpu.ram()[0x0300], cpu.ram()[0x0301] = 0x34, 0x12
+			So(run(0x6c, 0x00, 0x03), ShouldResemble, []busCycle{
+				r(0x0200, 0x6c), r(0x0201, 0x00), r(0x0202, 0x03), r(0x0300, 0x34), r(0x0301, 0x12),
+			})
+		})
+	})
+
+	Convey("should agree with the fast executor", t, func() {
+		// newPair makes two cpus in the same state with memory filled with a pattern so every pointer is somewhere interesting
+		newPair := func(op byte, index byte) (*CPU, *CPU) {
+			fast, accurate := newCPU(), newCPU()
+			for _, c := range []*CPU{fast, accurate} {
+				for i := range c.ram() {
+					c.ram()[i] = byte(i*7 + i>>8)
+				}
+				c.ram()[0x0200], c.ram()[0x0201], c.ram()[0x0202] = op, 0xf0, 0x03
+				c.pc, c.s, c.a, c.x, c.y = 0x0200, 0xf0, 0x5a, index, index
+				c.status.Set(0x24)
+			}
+			accurate.accurate = true
+			return fast, accurate
+		}
+
+		for op := 0; op < 256; op++ { // including the JAMs, which take the same cycles to halt
+			for _, index := range []byte{0x00, 0x20} { // 0x20 makes $03F0 cross a page
+				fast, accurate := newPair(byte(op), index)
+				fastInfo, _ := fast.Step()
+				accurateInfo, _ := accurate.Step()
+
+				name := fmt.Sprintf("%02x %v index %02x", op, fast.opcodes[byte(op)].Name, index)
+				So(name+" "+accurateInfo.String(), ShouldEqual, name+" "+fastInfo.String())
+				So(name+" "+accurate.String(), ShouldEqual, name+" "+fast.String())
+				So(*accurate.ram() == *fast.ram(), ShouldBeTrue)
+			}
+		}
+	})
+}
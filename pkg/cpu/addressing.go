@@ -0,0 +1,139 @@
+package cpu
+
+import "fmt"
+
+// Addressing modes decide where an instruction's operand is. [CPU.address] resolves them all for both executors.
+//
+// Anything that indexes or follows a pointer in zero page wraps around inside zero page: zp,X and zp,Y,
+// and the pointers of (zp,X), (zp),Y and (zp), whose high byte comes from $00 when the low byte is at $FF.
+// https://www.nesdev.org/wiki/CPU_addressing_modes
+
+const (
+	implicit = iota // implied?
+	accumulator
+	immediate
+	zeroPage
+	zeroPageX
+	zeroPageY
+	relative
+	absolute
+	absoluteX
+	absoluteY
+	indirect
+	indirectX
+	indirectY
+	// the 65C02's new modes
+	zeroPageIndirect  // (zp), like (zp),Y without the Y
+	absoluteIndirectX // (abs,X), only used by JMP
+	zeroPageRelative  // zp,rel, only used by BBR and BBS which test a bit in zero page and branch
+)
+
+var modes = map[int]string{
+	implicit:    "implicit",
+	accumulator: "accumulator",
+	immediate:   "immediate",
+	zeroPage:    "zeroPage",
+	zeroPageX:   "zeroPageX",
+	zeroPageY:   "zeroPageY",
+	relative:    "relative",
+	absolute:    "absolute",
+	absoluteX:   "absoluteX",
+	absoluteY:   "absoluteY",
+	indirect:    "indirect",
+	indirectX:   "indirectX", // indexedIndirect, (Indirect,X)
+	indirectY:   "indirectY", // indirectIndexed, (Indirect),Y
+
+	zeroPageIndirect:  "zeroPageIndirect",
+	absoluteIndirectX: "absoluteIndirectX",
+	zeroPageRelative:  "zeroPageRelative",
+}
+
+// address resolves the operand of op, whose operand bytes start at nPC, into the opDat for its handler.
+// crossed reports whether adding an index crossed a page, which costs a cycle for the [opcode.PageCross] reads.
+//
+// In accurate mode it also does the dummy reads the 6502 does while it works out the address, see [CPU.stepAccurate].
+func (cpu *CPU) address(op *opcode, nPC uint16) (dat opDat, crossed bool) {
+	dat.mode = op.Mode
+	switch op.Mode {
+	case implicit, accumulator:
+		if cpu.accurate {
+			cpu.read(nPC) // every instruction reads the byte after the opcode even if it has no operand
+		}
+	case immediate:
+		dat.addr = nPC // the handler's read is the operand fetch
+	case zeroPage:
+		dat.addr = uint16(cpu.read(nPC))
+	case zeroPageX:
+		dat.addr = cpu.zeroPageIndex(cpu.read(nPC), cpu.x)
+	case zeroPageY: // This mode can only be used with the LDX and STX instructions.
+		dat.addr = cpu.zeroPageIndex(cpu.read(nPC), cpu.y)
+	case relative:
+		dat.addr = nPC + 1 + uint16(int8(cpu.read(nPC))) // the "byte" read is really a signed int8. Interpret as int8 then cast to unsigned 2s complement and account for the instruction length.
+	case absolute:
+		dat.addr = cpu.read16(nPC)
+	case absoluteX:
+		dat.addr, crossed = cpu.index(op, cpu.read16(nPC), cpu.x)
+	case absoluteY:
+		dat.addr, crossed = cpu.index(op, cpu.read16(nPC), cpu.y)
+	// JMP is the only 6502 instruction to support indirection.
+	// The instruction contains a 16 bit address which identifies the location of the least significant byte of another 16 bit memory address which is the real target of the instruction.
+	case indirect:
+		if cpu.variant == Variant65C02 {
+			dat.addr = cpu.read16(cpu.read16(nPC))
+		} else {
+			dat.addr = cpu.read16Wrap(cpu.read16(nPC)) // the pointer's high byte doesn't carry into the next page, see read16Wrap
+		}
+	// Indexed indirect addressing is normally used in conjunction with a table of address held on zero page.
+	// The address of the table is taken from the instruction and the X register added to it (with zero page wrap around) to give the location of the least significant byte of the target address.
+	case indirectX:
+		dat.addr = cpu.readPointer(byte(cpu.zeroPageIndex(cpu.read(nPC), cpu.x)))
+	// Indirect indirect addressing is the most common indirection mode used on the 6502.
+	// In instruction contains the zero page location of the least significant byte of 16 bit address. The Y register is dynamically added to this value to generated the actual target address for operation.
+	case indirectY:
+		dat.addr, crossed = cpu.index(op, cpu.readPointer(cpu.read(nPC)), cpu.y)
+	case zeroPageIndirect:
+		dat.addr = cpu.readPointer(cpu.read(nPC))
+	case absoluteIndirectX:
+		dat.addr = cpu.read16(cpu.read16(nPC) + uint16(cpu.x))
+	case zeroPageRelative:
+		dat.addr = uint16(cpu.read(nPC))
+		dat.target = nPC + 2 + uint16(int8(cpu.read(nPC+1)))
+	default:
+		panic(fmt.Errorf("unknown mode for op: %+v, cpu: %+v", op, cpu))
+	}
+	return dat, crossed
+}
+
+// zeroPageIndex adds index to the zero page address zp, wrapping around inside zero page.
+//
+// In accurate mode the CPU reads zp while it does the add.
+func (cpu *CPU) zeroPageIndex(zp, index byte) uint16 {
+	if cpu.accurate {
+		cpu.read(uint16(zp))
+	}
+	return uint16(zp + index)
+}
+
+// readPointer reads the little endian pointer at zp. If zp is $FF the high byte comes from $00, not $0100.
+func (cpu *CPU) readPointer(zp byte) uint16 {
+	lo, hi := uint16(cpu.read(uint16(zp))), uint16(cpu.read(uint16(zp+1)))
+	return hi<<8 | lo
+}
+
+// index adds index to base for the indexed modes and reports whether it crossed a page.
+//
+// In accurate mode, while the high byte is fixed up the CPU reads from the address with the unfixed high byte.
+// Reads skip that cycle when no fixup was needed, but stores and read-modify-writes always do it.
+func (cpu *CPU) index(op *opcode, base uint16, index byte) (uint16, bool) {
+	addr := base + uint16(index)
+	crossed := pageCrossed(base, addr)
+	if cpu.accurate && (crossed || !op.PageCross) {
+		cpu.read(base&0xFF00 | addr&0x00FF)
+	}
+	return addr, crossed
+}
+
+// pageCrossed reports whether a and b are on different pages, i.e. their high bytes differ.
+func pageCrossed(a, b uint16) bool {
+	return a&0xFF00 != b&0xFF00
+}
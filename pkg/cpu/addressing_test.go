@@ -0,0 +1,116 @@
+package cpu
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAddressing(t *testing.T) {
+	Convey("should resolve every mode at page boundaries", t, func() {
+		type mem map[uint16]byte
+		for _, tc := range []struct {
+			name    string
+			mode    int
+			nPC     uint16 // where the operand bytes are
+			operand []byte
+			x, y    byte
+			mem     mem // pointers
+			cmos    bool
+			addr    uint16
+			crossed bool
+		}{
+			{name: "immediate", mode: immediate, operand: []byte{0x12}, addr: 0x0201},
+			{name: "immediate at the end of memory", mode: immediate, nPC: 0xFFFF, operand: []byte{0x12}, addr: 0xFFFF},
+
+			{name: "zp", mode: zeroPage, operand: []byte{0xFF}, addr: 0x00FF},
+			{name: "zp,X", mode: zeroPageX, operand: []byte{0x80}, x: 0x7F, addr: 0x00FF},
+			{name: "zp,X wraps", mode: zeroPageX, operand: []byte{0xFF}, x: 0x01, addr: 0x0000},
+			{name: "zp,X wraps all the way", mode: zeroPageX, operand: []byte{0x80}, x: 0xFF, addr: 0x007F},
+			{name: "zp,Y wraps", mode: zeroPageY, operand: []byte{0xFF}, y: 0x02, addr: 0x0001},
+
+			{name: "rel forward", mode: relative, operand: []byte{0x10}, addr: 0x0212},
+			{name: "rel forward across a page", mode: relative, nPC: 0x02FE, operand: []byte{0x01}, addr: 0x0300},
+			{name: "rel backward across a page", mode: relative, operand: []byte{0xFC}, addr: 0x01FE},
+			{name: "rel wraps memory", mode: relative, nPC: 0xFFFE, operand: []byte{0x02}, addr: 0x0001},
+
+			{name: "abs", mode: absolute, operand: []byte{0xFF, 0x12}, addr: 0x12FF},
+			{name: "abs operand across a page", mode: absolute, nPC: 0x02FF, operand: []byte{0x34, 0x12}, addr: 0x1234},
+			{name: "abs,X", mode: absoluteX, operand: []byte{0x00, 0x12}, x: 0xFF, addr: 0x12FF},
+			{name: "abs,X across a page", mode: absoluteX, operand: []byte{0xFF, 0x12}, x: 0x01, addr: 0x1300, crossed: true},
+			{name: "abs,X wraps memory", mode: absoluteX, operand: []byte{0xFF, 0xFF}, x: 0x01, addr: 0x0000, crossed: true},
+			{name: "abs,Y", mode: absoluteY, operand: []byte{0x80, 0x12}, y: 0x7F, addr: 0x12FF},
+			{name: "abs,Y across a page", mode: absoluteY, operand: []byte{0x80, 0x12}, y: 0x80, addr: 0x1300, crossed: true},
+
+			{name: "(abs)", mode: indirect, operand: []byte{0x34, 0x12}, mem: mem{0x1234: 0x78, 0x1235: 0x56}, addr: 0x5678},
+			{name: "(abs) doesn't carry into the next page", mode: indirect, operand: []byte{0xFF, 0x12}, mem: mem{0x12FF: 0x78, 0x1200: 0x56, 0x1300: 0x99}, addr: 0x5678},
+			{name: "(abs) on the 65C02 does", mode: indirect, operand: []byte{0xFF, 0x12}, mem: mem{0x12FF: 0x78, 0x1200: 0x99, 0x1300: 0x56}, cmos: true, addr: 0x5678},
+
+			{name: "(zp,X)", mode: indirectX, operand: []byte{0x40}, x: 0x02, mem: mem{0x42: 0x34, 0x43: 0x12}, addr: 0x1234},
+			{name: "(zp,X) pointer at $FF wraps", mode: indirectX, operand: []byte{0xFF}, mem: mem{0xFF: 0x34, 0x00: 0x12, 0x100: 0x99}, addr: 0x1234},
+			{name: "(zp,X) index wraps", mode: indirectX, operand: []byte{0x80}, x: 0x80, mem: mem{0x00: 0x34, 0x01: 0x12}, addr: 0x1234},
+			{name: "(zp,X) index wraps to $FF", mode: indirectX, operand: []byte{0xFE}, x: 0x01, mem: mem{0xFF: 0x34, 0x00: 0x12, 0x100: 0x99}, addr: 0x1234},
+
+			{name: "(zp),Y", mode: indirectY, operand: []byte{0x40}, y: 0x02, mem: mem{0x40: 0x34, 0x41: 0x12}, addr: 0x1236},
+			{name: "(zp),Y pointer at $FF wraps", mode: indirectY, operand: []byte{0xFF}, mem: mem{0xFF: 0x34, 0x00: 0x12, 0x100: 0x99}, addr: 0x1234},
+			{name: "(zp),Y across a page", mode: indirectY, operand: []byte{0x40}, y: 0x01, mem: mem{0x40: 0xFF, 0x41: 0x12}, addr: 0x1300, crossed: true},
+			{name: "(zp),Y pointer wraps and crosses", mode: indirectY, operand: []byte{0xFF}, y: 0xFF, mem: mem{0xFF: 0x01, 0x00: 0x12}, addr: 0x1300, crossed: true},
+			{name: "(zp),Y wraps memory", mode: indirectY, operand: []byte{0x40}, y: 0x01, mem: mem{0x40: 0xFF, 0x41: 0xFF}, addr: 0x0000, crossed: true},
+
+			{name: "(zp)", mode: zeroPageIndirect, operand: []byte{0x40}, mem: mem{0x40: 0x34, 0x41: 0x12}, cmos: true, addr: 0x1234},
+			{name: "(zp) pointer at $FF wraps", mode: zeroPageIndirect, operand: []byte{0xFF}, mem: mem{0xFF: 0x34, 0x00: 0x12, 0x100: 0x99}, cmos: true, addr: 0x1234},
+			{name: "(abs,X)", mode: absoluteIndirectX, operand: []byte{0xFF, 0x12}, x: 0x01, mem: mem{0x1300: 0x34, 0x1301: 0x12}, cmos: true, addr: 0x1234},
+
+			{name: "zp,rel", mode: zeroPageRelative, operand: []byte{0xFF, 0xFD}, cmos: true, addr: 0x00FF},
+		} {
+			cpu := newCPU()
+			if tc.cmos {
+				cpu.variant = Variant65C02
+			}
+			nPC := tc.nPC
+			if nPC == 0 {
+				nPC = 0x0201
+			}
+			for i, b := range tc.operand {
+				cpu.ram()[nPC+uint16(i)] = b
+			}
+			for addr, b := range tc.mem {
+				cpu.ram()[addr] = b
+			}
+			cpu.x, cpu.y = tc.x, tc.y
+
+			dat, crossed := cpu.address(&opcode{Mode: tc.mode}, nPC)
+			So(fmt.Sprintf("%v: 0x%04x crossed %v", tc.name, dat.addr, crossed), ShouldEqual, fmt.Sprintf("%v: 0x%04x crossed %v", tc.name, tc.addr, tc.crossed))
+			So(dat.mode, ShouldEqual, tc.mode)
+		}
+	})
+
+	Convey("should branch from zp,rel relative to the end of the instruction", t, func() {
+		cpu := newCPU()
+		cpu.ram()[0x0201], cpu.ram()[0x0202] = 0x40, 0xFD
+		dat, _ := cpu.address(&opcode{Mode: zeroPageRelative}, 0x0201)
+		So(dat.addr, ShouldEqual, 0x0040)
+		So(dat.target, ShouldEqual, 0x0200) // 0x0203 - 3
+	})
+
+	Convey("should wrap zero page pointers when running", t, func() {
+		cpu := newCPU()
+		cpu.write16(0xFFFE, 0x1234)
+		// the program is at 0 so its first opcode is the pointer's high byte, and $0100 is the high byte if it doesn't wrap
+		cpu.ram()[0x00FF], cpu.ram()[0x0100] = 0x00, 0x04
+		cpu.ram()[0x0400] = 0x22
+
+		Convey("(zp,X)", func() {
+			cpu.ram()[0xA200] = 0x11
+			runProgram(cpu, []byte{0xa2, 0x01, 0xa1, 0xfe, 0x00}) // LDX #1, LDA ($FE,X)
+			So(cpu.a, ShouldEqual, 0x11)
+		})
+
+		Convey("(zp),Y", func() {
+			cpu.ram()[0xA000] = 0x11
+			runProgram(cpu, []byte{0xa0, 0x00, 0xb1, 0xff, 0x00}) // LDY #0, LDA ($FF),Y
+			So(cpu.a, ShouldEqual, 0x11)
+		})
+	})
+}
@@ -0,0 +1,122 @@
+package cpu
+
+import "fmt"
+
+// Option configures a CPU made by [New].
+type Option func(*CPU)
+
+// WithBus attaches the CPU to bus instead of a flat 64KiB [RAM].
+func WithBus(bus Bus) Option {
+	return func(cpu *CPU) {
+		cpu.SetBus(bus)
+	}
+}
+
+// WithStrict makes the CPU stop on illegal opcodes instead of running them, see [StopIllegal].
+func WithStrict(strict bool) Option {
+	return func(cpu *CPU) {
+		cpu.strict = strict
+	}
+}
+
+// WithMagic sets the "magic constant" the unstable ANE and LXA opcodes use, which differs between chips.
+func WithMagic(magic byte) Option {
+	return func(cpu *CPU) {
+		cpu.magic = magic
+	}
+}
+
+// WithAccurate switches the CPU to the bus-cycle-accurate executor, which does every read and write on the cycle the real 6502 does.
+// The accurate executor only knows the NMOS bus patterns, so [New] panics if it's asked for with the 65C02.
+func WithAccurate(accurate bool) Option {
+	return func(cpu *CPU) {
+		cpu.accurate = accurate
+	}
+}
+
+// WithCycleHook calls fn for every bus cycle with the address, the data and whether it was a write.
+// It implies [WithAccurate] since the fast executor doesn't do its accesses cycle by cycle, so it can't be used with the 65C02 either.
+func WithCycleHook(fn func(addr uint16, dat byte, write bool)) Option {
+	return func(cpu *CPU) {
+		cpu.accurate = true
+		cpu.onCycle = fn
+	}
+}
+
+// New makes a CPU and powers it on, which runs the reset sequence so it starts at the reset vector.
+//
+// By default it runs on a flat 64KiB [RAM] with the fast executor and runs illegal opcodes.
+// It panics if the accurate executor is asked for with the 65C02, see [WithAccurate].
+func New(opts ...Option) *CPU {
+	cpu := newCPU()
+	for _, opt := range opts {
+		opt(cpu)
+	}
+	if cpu.accurate && cpu.variant == Variant65C02 {
+		panic(fmt.Errorf("the accurate executor doesn't support the %v", cpu.variant))
+	}
+	cpu.reset()
+	return cpu
+}
+
+// Reset runs the 6502's reset sequence. The pc is loaded from the reset vector at 0xFFFC/D and I is set.
+// It's the only way out of a JAM.
+func (cpu *CPU) Reset() {
+	cpu.reset()
+}
+
+// Registers is a snapshot of the CPU's registers.
+type Registers struct {
+	PC      uint16
+	A, X, Y byte
+	S       byte // the stack pointer, the stack is at 0x0100 | S
+	P       Status
+}
+
+// Registers returns a snapshot of the registers.
+func (cpu *CPU) Registers() Registers {
+	return Registers{PC: cpu.pc, A: cpu.a, X: cpu.x, Y: cpu.y, S: cpu.s, P: cpu.status}
+}
+
+// SetRegisters sets all the registers at once, e.g. from a snapshot taken with [CPU.Registers].
+// The I flag takes effect straight away, the next [CPU.Step] doesn't see the old one like it would after CLI or SEI.
+func (cpu *CPU) SetRegisters(regs Registers) {
+	cpu.pc, cpu.a, cpu.x, cpu.y, cpu.s, cpu.status = regs.PC, regs.A, regs.X, regs.Y, regs.S, regs.P
+	cpu.pollI = regs.P.Flag(posI)
+}
+
+// PC returns the program counter.
+func (cpu *CPU) PC() uint16 {
+	return cpu.pc
+}
+
+// SetPC sets the program counter, so the next [CPU.Step] runs the instruction at pc.
+func (cpu *CPU) SetPC(pc uint16) {
+	cpu.pc = pc
+}
+
+// Status returns the processor status register.
+func (cpu *CPU) Status() Status {
+	return cpu.status
+}
+
+// Cycles returns the number of cycles the CPU has run since it was made.
+func (cpu *CPU) Cycles() uint64 {
+	return cpu.cycles
+}
+
+// Halted reports whether a JAM opcode has halted the CPU.
+func (cpu *CPU) Halted() bool {
+	return cpu.halted
+}
+
+// Peek returns the byte at addr without it counting as a CPU cycle.
+// It only avoids side effects on the bus if the bus is a [Peeker], otherwise it's a Read, which could e.g. acknowledge a register.
+func (cpu *CPU) Peek(addr uint16) byte {
+	return cpu.peek(addr)
+}
+
+// Poke writes dat to addr through the bus without it counting as a CPU cycle.
+func (cpu *CPU) Poke(addr uint16, dat byte) {
+	cpu.bus.Write(addr, dat)
+}
@@ -0,0 +1,100 @@
+package cpu
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAPI(t *testing.T) {
+	Convey("should work from outside the package", t, func() {
+		Convey("new powers on through reset", func() {
+			cpu := New()
+			So(cpu.PC(), ShouldEqual, 0x0000)
+			So(cpu.Registers().S, ShouldEqual, 0xFD)
+			So(cpu.Status().Flag(FlagI), ShouldBeTrue)
+			So(cpu.Cycles(), ShouldEqual, 7)
+			So(cpu.Halted(), ShouldBeFalse)
+		})
+
+		Convey("options configure the cpu", func() {
+			ram := &RAM{}
+			ram[0xFFFC], ram[0xFFFD] = 0x00, 0x80
+			var cycles int
+			cpu := New(WithBus(ram), WithStrict(true), WithMagic(0xFF), WithCycleHook(func(uint16, byte, bool) { cycles++ }))
+			So(cpu.PC(), ShouldEqual, 0x8000)
+			So(cpu.strict, ShouldBeTrue)
+			So(cpu.magic, ShouldEqual, 0xFF)
+			So(cpu.accurate, ShouldBeTrue)
+			So(cycles, ShouldEqual, 7)
+
+			So(New(WithAccurate(true)).accurate, ShouldBeTrue)
+		})
+
+		Convey("registers round trip", func() {
+			cpu := New()
+			regs := Registers{PC: 0x1234, A: 1, X: 2, Y: 3, S: 0xF0}
+			regs.P.Set(FlagN | FlagC)
+			cpu.SetRegisters(regs)
+			So(cpu.Registers(), ShouldResemble, regs)
+			So(cpu.a, ShouldEqual, 1)
+			So(cpu.status.Flag(posN), ShouldBeTrue)
+
+			cpu.SetPC(0x4321)
+			So(cpu.Registers().PC, ShouldEqual, 0x4321)
+		})
+
+		Convey("setting I masks an irq from the next step", func() {
+			cpu := New()
+			for _, addr := range []uint16{0x0000, 0x0500, 0x0501} {
+				cpu.Poke(addr, 0xea) // NOP
+			}
+			cpu.Poke(0xFFFE, 0x00)
+			cpu.Poke(0xFFFF, 0x05)
+			cpu.SetIRQ(true)
+			cpu.Step() // the first instruction after reset, with I set
+
+			regs := cpu.Registers()
+			regs.P.SetFlag(FlagI, false)
+			cpu.SetRegisters(regs)
+			info, _ := cpu.Step()
+			So(info.Name, ShouldEqual, "IRQ")
+
+			regs = cpu.Registers()
+			regs.P.SetFlag(FlagI, false)
+			cpu.SetRegisters(regs)
+			cpu.Step() // the handler's first instruction
+			regs = cpu.Registers()
+			regs.P.SetFlag(FlagI, true)
+			cpu.SetRegisters(regs)
+			info, _ = cpu.Step()
+			So(info.Interrupt, ShouldBeFalse)
+		})
+
+		Convey("peek and poke don't take cycles", func() {
+			cpu := New(WithAccurate(true))
+			cpu.Poke(0x0200, 0xAB)
+			So(cpu.Peek(0x0200), ShouldEqual, 0xAB)
+			So(cpu.Cycles(), ShouldEqual, 7)
+		})
+
+		Convey("reset gets out of a jam", func() {
+			cpu := New()
+			cpu.Poke(0x0000, 0x02) // JAM
+			cpu.Step()
+			So(cpu.Halted(), ShouldBeTrue)
+			cpu.Reset()
+			So(cpu.Halted(), ShouldBeFalse)
+		})
+
+		Convey("status converts to and from the flag byte", func() {
+			var status Status
+			status.Set(FlagN | FlagU | FlagI | FlagC)
+			So(status, ShouldEqual, Status(FlagN|FlagU|FlagI|FlagC))
+			So(status.Get(), ShouldEqual, 0xA5)
+			So(status.String(), ShouldEqual, "NvUbdIzC")
+			status.Clear()
+			So(status.String(), ShouldEqual, "nvubdizc")
+		})
+	})
+}
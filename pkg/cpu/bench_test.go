@@ -0,0 +1,82 @@
+package cpu
+
+import (
+	"context"
+	"testing"
+)
+
+// ntscClock is the NES's CPU clock in MHz, what the emulator has to beat to run in real time.
+const ntscClock = 1.789773
+
+// benchProgram is a loop over a mix of addressing modes, arithmetic, read-modify-writes, branches and the stack.
+var benchProgram = []byte{
+	0xa2, 0x00, // 0200: LDX #0
+	0xbd, 0x00, 0x03, // 0202: LDA $0300,X
+	0x69, 0x01, // 0205: ADC #1
+	0x9d, 0x00, 0x04, // 0207: STA $0400,X
+	0xb1, 0x10, // 020A: LDA ($10),Y
+	0x45, 0x20, // 020C: EOR $20
+	0x06, 0x21, // 020E: ASL $21
+	0xe8,       // 0210: INX
+	0xd0, 0xef, // 0211: BNE $0202
+	0x20, 0x20, 0x02, // 0213: JSR $0220
+	0x4c, 0x00, 0x02, // 0216: JMP $0200
+	0xea, 0xea, 0xea, 0xea, 0xea, 0xea, // 0219: padding
+	0x48, // 0220: PHA
+	0x68, // 0221: PLA
+	0x60, // 0222: RTS
+}
+
+func newBenchCPU(opts ...Option) *CPU {
+	ram := &RAM{}
+	copy(ram[0x0200:], benchProgram)
+	ram[0x10], ram[0x11] = 0x00, 0x05
+	ram[0xFFFC], ram[0xFFFD] = 0x00, 0x02
+	return New(append([]Option{WithBus(ram)}, opts...)...)
+}
+
+// reportMHz reports the emulated clock speed for the cycles run since start, and how many times faster than the NES that is.
+func reportMHz(b *testing.B, cpu *CPU, start uint64) {
+	mhz := float64(cpu.cycles-start) / b.Elapsed().Seconds() / 1e6
+	b.ReportMetric(mhz, "MHz")
+	b.ReportMetric(mhz/ntscClock, "x-realtime")
+}
+
+func benchmarkStep(b *testing.B, opts ...Option) {
+	cpu := newBenchCPU(opts...)
+	start := cpu.cycles
+	b.ResetTimer()
+	for range b.N {
+		cpu.Step()
+	}
+	reportMHz(b, cpu, start)
+}
+
+func BenchmarkStep(b *testing.B) {
+	benchmarkStep(b)
+}
+
+func BenchmarkStepAccurate(b *testing.B) {
+	benchmarkStep(b, WithAccurate(true))
+}
+
+func BenchmarkStepNMOS(b *testing.B) {
+	benchmarkStep(b, WithVariant(VariantNMOS))
+}
+
+func BenchmarkStep65C02(b *testing.B) {
+	benchmarkStep(b, WithVariant(Variant65C02))
+}
+
+// BenchmarkRun runs a frame's worth of cycles at a time, the way a NES would.
+func BenchmarkRun(b *testing.B) {
+	const frame = 29781 // NTSC CPU cycles per frame
+	cpu := newBenchCPU()
+	start := cpu.cycles
+	ctx := context.Background()
+	b.ResetTimer()
+	for range b.N {
+		cpu.Run(ctx, frame)
+	}
+	reportMHz(b, cpu, start)
+}
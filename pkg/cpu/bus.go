@@ -0,0 +1,46 @@
+package cpu
+
+// Bus is everything the CPU can address. Every read and write the CPU makes goes through it,
+// so RAM mirroring, memory mapped registers and cartridge mappers can all hang off it.
+//
+// On the NES the last 6 bytes 0xFFFA-0xFFFF must be programmed with the addresses of
+// the non-maskable interrupt handler (0xFFFA/B),
+// the power on reset location (0xFFFC/D)
+// and the BRK/interrupt request handler (0xFFFE/F).
+//
+// https://www.nesdev.org/obelisk-6502-guide/architecture.html
+type Bus interface {
+	Read(addr uint16) byte
+	Write(addr uint16, dat byte)
+}
+
+// Peeker is implemented by a [Bus] that can read without side effects, e.g. without acknowledging a register, for debuggers and tracing.
+// A bus without it is peeked with Read.
+type Peeker interface {
+	Peek(addr uint16) byte
+}
+
+// RAM is a flat 64KiB of memory with nothing mapped into it, the default bus.
+//
+// 0x0000-0x00FF is zero page (first page)
+//
+// 0x0100-0x01FF is system stack (second page)
+type RAM [0xFFFF + 1]byte
+
+func (ram *RAM) Read(addr uint16) byte {
+	return ram[addr]
+}
+
+func (ram *RAM) Write(addr uint16, dat byte) {
+	ram[addr] = dat
+}
+
+func (ram *RAM) Peek(addr uint16) byte {
+	return ram[addr]
+}
+
+// SetBus attaches the CPU to bus, which it will do all its reads and writes through from then on.
+func (cpu *CPU) SetBus(bus Bus) {
+	cpu.bus = bus
+	cpu.peeker, _ = bus.(Peeker)
+}
@@ -0,0 +1,59 @@
+package cpu
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// countingBus is RAM with a register at 0x2000 that counts how many times it's been read.
+type countingBus struct {
+	RAM
+	reads byte
+}
+
+func (bus *countingBus) Read(addr uint16) byte {
+	if addr == 0x2000 {
+		bus.reads += 1
+		return bus.reads
+	}
+	return bus.RAM.Read(addr)
+}
+
+// readOnlyBus is RAM that can't be peeked.
+type readOnlyBus struct {
+	ram RAM
+}
+
+func (bus *readOnlyBus) Read(addr uint16) byte       { return bus.ram.Read(addr) }
+func (bus *readOnlyBus) Write(addr uint16, dat byte) { bus.ram.Write(addr, dat) }
+
+func TestBus(t *testing.T) {
+	Convey("should go through the bus", t, func() {
+		cpu := newCPU()
+		bus := &countingBus{}
+		cpu.SetBus(bus)
+
+		Convey("reads and writes hit the bus", func() {
+			copy(bus.RAM[:], []byte{0xad, 0x00, 0x20, 0x8d, 0x00, 0x03}) // LDA $2000, STA $0300
+			cpu.Step()
+			cpu.Step()
+			So(cpu.a, ShouldEqual, 1)
+			So(bus.RAM[0x0300], ShouldEqual, 1)
+			So(bus.reads, ShouldEqual, 1)
+		})
+
+		Convey("peek doesn't touch registers", func() {
+			So(cpu.peek(0x2000), ShouldEqual, 0) // the RAM underneath
+			So(bus.reads, ShouldEqual, 0)
+		})
+
+		Convey("peek falls back to read without a Peeker", func() {
+			ro := &readOnlyBus{}
+			ro.ram[0x1234] = 0x56
+			cpu.SetBus(ro)
+			So(cpu.peeker, ShouldBeNil)
+			So(cpu.peek(0x1234), ShouldEqual, 0x56)
+		})
+	})
+}
@@ -0,0 +1,108 @@
+package cpu
+
+// The WDC 65C02 is the CMOS 6502. It keeps every legal NMOS opcode and adds new ones in the holes the illegal opcodes were in,
+// plus the (zp), (abs,X) and zp,rel modes. Every other hole is a NOP with its own size and cycle count.
+// https://www.westerndesigncenter.com/wdc/documentation/w65c02s.pdf
+// http://www.6502.org/tutorials/65c02opcodes.html
+
+// tsb - Test and Set Bits (65C02)
+//
+// Z = A&M, M = M|A
+//
+// Sets the zero flag like BIT then sets the bits of memory that are set in the accumulator.
+func (cpu *CPU) tsb(dat opDat) {
+	val := cpu.read(dat.addr)
+	cpu.setZ(cpu.a & val)
+	cpu.write(dat.addr, val|cpu.a)
+}
+
+// trb - Test and Reset Bits (65C02)
+//
+// Z = A&M, M = M&^A
+//
+// Sets the zero flag like BIT then clears the bits of memory that are set in the accumulator.
+func (cpu *CPU) trb(dat opDat) {
+	val := cpu.read(dat.addr)
+	cpu.setZ(cpu.a & val)
+	cpu.write(dat.addr, val&^cpu.a)
+}
+
+// stz - Store Zero (65C02)
+//
+// M = 0
+func (cpu *CPU) stz(dat opDat) {
+	cpu.write(dat.addr, 0)
+}
+
+// phx - Push X Register (65C02)
+func (cpu *CPU) phx(opDat) {
+	cpu.push(cpu.x)
+}
+
+// phy - Push Y Register (65C02)
+func (cpu *CPU) phy(opDat) {
+	cpu.push(cpu.y)
+}
+
+// plx - Pull X Register (65C02)
+//
+// Pulls an 8 bit value from the stack and into the X register. The zero and negative flags are set as appropriate.
+func (cpu *CPU) plx(opDat) {
+	cpu.x = cpu.pop()
+	cpu.setZN(cpu.x)
+}
+
+// ply - Pull Y Register (65C02)
+//
+// Pulls an 8 bit value from the stack and into the Y register. The zero and negative flags are set as appropriate.
+func (cpu *CPU) ply(opDat) {
+	cpu.y = cpu.pop()
+	cpu.setZN(cpu.y)
+}
+
+// bra - Branch Always (65C02)
+func (cpu *CPU) bra(dat opDat) {
+	cpu.branch(true, dat)
+}
+
+// wai - Wait for Interrupt (65C02)
+//
+// Stops the CPU until NMI or IRQ is asserted. If I is set an IRQ doesn't get serviced and the CPU just carries on after the WAI.
+func (cpu *CPU) wai(opDat) {
+	cpu.waiting = true
+}
+
+// stp - Stop the Clock (65C02)
+//
+// Stops the CPU until a reset, same as a JAM on the NMOS chips.
+func (cpu *CPU) stp(dat opDat) {
+	cpu.jam(dat)
+}
+
+// rmb returns RMBn - Reset Memory Bit (65C02), which clears bit n of the zero page byte.
+func (cpu *CPU) rmb(n int) func(opDat) {
+	return func(dat opDat) {
+		cpu.write(dat.addr, cpu.read(dat.addr)&^(1<<n))
+	}
+}
+
+// smb returns SMBn - Set Memory Bit (65C02), which sets bit n of the zero page byte.
+func (cpu *CPU) smb(n int) func(opDat) {
+	return func(dat opDat) {
+		cpu.write(dat.addr, cpu.read(dat.addr)|1<<n)
+	}
+}
+
+// bbr returns BBRn - Branch on Bit Reset (65C02), which branches if bit n of the zero page byte is clear.
+func (cpu *CPU) bbr(n int) func(opDat) {
+	return func(dat opDat) {
+		cpu.branch(cpu.read(dat.addr)&(1<<n) == 0, opDat{addr: dat.target, pc: dat.pc})
+	}
+}
+
+// bbs returns BBSn - Branch on Bit Set (65C02), which branches if bit n of the zero page byte is set.
+func (cpu *CPU) bbs(n int) func(opDat) {
+	return func(dat opDat) {
+		cpu.branch(cpu.read(dat.addr)&(1<<n) != 0, opDat{addr: dat.target, pc: dat.pc})
+	}
+}
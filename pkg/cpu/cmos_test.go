@@ -0,0 +1,164 @@
+package cpu
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCMOS(t *testing.T) {
+	Convey("should run 65C02 opcodes", t, func() {
+		cpu := New(WithVariant(Variant65C02))
+		cpu.s = 0xff
+
+		// run steps the instruction at $0200 and returns the cycles it took
+		run := func(program ...byte) int {
+			copy(cpu.ram()[0x0200:], program)
+			cpu.pc = 0x0200
+			return stepCycles(cpu.Step())
+		}
+
+		Convey("jmp indirect doesn't wrap the pointer", func() {
+			cpu.ram()[0x02FF], cpu.ram()[0x0300], cpu.ram()[0x0200] = 0x00, 0x04, 0x80
+			So(run(0x6c, 0xff, 0x02), ShouldEqual, 6) // JMP ($02FF)
+			So(cpu.pc, ShouldEqual, 0x0400)
+		})
+
+		Convey("jmp indexed indirect", func() {
+			cpu.x = 0x04
+			cpu.ram()[0x0304], cpu.ram()[0x0305] = 0x34, 0x12
+			So(run(0x7c, 0x00, 0x03), ShouldEqual, 6) // JMP ($0300,X)
+			So(cpu.pc, ShouldEqual, 0x1234)
+		})
+
+		Convey("zero page indirect wraps in zero page", func() {
+			cpu.ram()[0x00FF], cpu.ram()[0x0000] = 0x00, 0x04
+			cpu.ram()[0x0400] = 0x99
+			So(run(0xb2, 0xff), ShouldEqual, 5) // LDA ($FF)
+			So(cpu.a, ShouldEqual, 0x99)
+			run(0x92, 0xfe) // STA ($FE) goes to 0x0000 from 0x00FE/F
+			So(cpu.ram()[0x0000], ShouldEqual, 0x99)
+		})
+
+		Convey("stz", func() {
+			cpu.ram()[0x0040], cpu.ram()[0x0345] = 0x11, 0x22
+			cpu.x = 0x05
+			So(run(0x64, 0x40), ShouldEqual, 3)
+			So(run(0x9e, 0x40, 0x03), ShouldEqual, 5)
+			So(cpu.ram()[0x0040], ShouldEqual, 0)
+			So(cpu.ram()[0x0345], ShouldEqual, 0)
+		})
+
+		Convey("tsb and trb", func() {
+			cpu.a = 0x0F
+			cpu.ram()[0x0040] = 0xF0
+			So(run(0x04, 0x40), ShouldEqual, 5) // TSB $40
+			So(cpu.ram()[0x0040], ShouldEqual, 0xFF)
+			So(cpu.status.Flag(posZ), ShouldBeTrue) // no bits in common before
+			run(0x14, 0x40)                         // TRB $40
+			So(cpu.ram()[0x0040], ShouldEqual, 0xF0)
+			So(cpu.status.Flag(posZ), ShouldBeFalse)
+		})
+
+		Convey("push and pull x and y", func() {
+			cpu.x, cpu.y = 0x80, 0x00
+			So(run(0xda), ShouldEqual, 3) // PHX
+			run(0x5a)                     // PHY
+			So(run(0xfa), ShouldEqual, 4) // PLX
+			So(cpu.x, ShouldEqual, 0x00)
+			So(cpu.status.Flag(posZ), ShouldBeTrue)
+			run(0x7a) // PLY
+			So(cpu.y, ShouldEqual, 0x80)
+			So(cpu.status.Flag(posN), ShouldBeTrue)
+		})
+
+		Convey("inc and dec the accumulator", func() {
+			cpu.a = 0xFF
+			So(run(0x1a), ShouldEqual, 2)
+			So(cpu.a, ShouldEqual, 0x00)
+			So(cpu.status.Flag(posZ), ShouldBeTrue)
+			run(0x3a)
+			So(cpu.a, ShouldEqual, 0xFF)
+		})
+
+		Convey("bit immediate only sets Z", func() {
+			cpu.a = 0x01
+			cpu.status.SetFlag(posN, false)
+			cpu.status.SetFlag(posV, true)
+			run(0x89, 0xC0)
+			So(cpu.status.Flag(posZ), ShouldBeTrue)
+			So(cpu.status.Flag(posN), ShouldBeFalse)
+			So(cpu.status.Flag(posV), ShouldBeTrue)
+		})
+
+		Convey("bra always branches", func() {
+			So(run(0x80, 0x10), ShouldEqual, 3)
+			So(cpu.pc, ShouldEqual, 0x0212)
+			So(run(0x80, 0x80), ShouldEqual, 4) // back to the page before
+			So(cpu.pc, ShouldEqual, 0x0182)
+		})
+
+		Convey("set and reset memory bits", func() {
+			run(0xd7, 0x40) // SMB5 $40
+			So(cpu.ram()[0x0040], ShouldEqual, 0x20)
+			run(0x57, 0x40) // RMB5 $40
+			So(cpu.ram()[0x0040], ShouldEqual, 0x00)
+		})
+
+		Convey("branch on memory bits", func() {
+			cpu.ram()[0x0040] = 0x04
+			So(run(0xaf, 0x40, 0x10), ShouldEqual, 6) // BBS2 $40
+			So(cpu.pc, ShouldEqual, 0x0213)
+			So(run(0x2f, 0x40, 0x10), ShouldEqual, 5) // BBR2 $40
+			So(cpu.pc, ShouldEqual, 0x0203)
+		})
+
+		Convey("shifts only take the index cycle when crossing a page", func() {
+			cpu.x = 0x01
+			So(run(0x1e, 0x00, 0x03), ShouldEqual, 6) // ASL $0300,X
+			So(run(0x1e, 0xff, 0x03), ShouldEqual, 7) // ASL $03FF,X
+			So(run(0xfe, 0x00, 0x03), ShouldEqual, 7) // INC $0300,X
+		})
+
+		Convey("the illegal NMOS opcodes are NOPs", func() {
+			cpu.a, cpu.x = 0x12, 0x34
+			So(run(0xa7, 0x40), ShouldEqual, 5) // RMB2 instead of LAX
+			So(run(0x03), ShouldEqual, 1)
+			So(cpu.pc, ShouldEqual, 0x0201)
+			So(run(0x02, 0xff), ShouldEqual, 2) // no more JAMs
+			So(cpu.pc, ShouldEqual, 0x0202)
+			So(run(0x5c, 0x00, 0x03), ShouldEqual, 8)
+			So(cpu.pc, ShouldEqual, 0x0203)
+			So(cpu.a, ShouldEqual, 0x12)
+			So(cpu.x, ShouldEqual, 0x34)
+			So(cpu.halted, ShouldBeFalse)
+			So(cpu.opcodes[0x03].Illegal, ShouldBeTrue)
+			So(cpu.opcodes[0x07].Illegal, ShouldBeFalse)
+		})
+
+		Convey("wai waits for an interrupt", func() {
+			cpu.status.SetFlag(posI, true)
+			run(0xcb, 0xe8) // WAI, INX
+			info, _ := cpu.Step()
+			So(info, ShouldResemble, StepInfo{PC: 0x0200, Opcode: 0xcb, Name: "WAI", Cycles: 1})
+			So(cpu.pc, ShouldEqual, 0x0201)
+
+			cpu.SetIRQ(true) // I is set so it just carries on
+			info, _ = cpu.Step()
+			So(info.Name, ShouldEqual, "INX")
+			So(cpu.x, ShouldEqual, 1)
+		})
+
+		Convey("stp stops until reset", func() {
+			run(0xdb)
+			So(cpu.halted, ShouldBeTrue)
+			_, err := cpu.Step()
+			So(err, ShouldEqual, ErrHalted)
+		})
+
+		Convey("the accurate executor doesn't do 65C02", func() {
+			So(func() { New(WithVariant(Variant65C02), WithAccurate(true)) }, ShouldPanic)
+			So(func() { New(WithVariant(Variant65C02), WithCycleHook(func(uint16, byte, bool) {})) }, ShouldPanic)
+		})
+	})
+}
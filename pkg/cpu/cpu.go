@@ -1,90 +1,70 @@
+// Package cpu emulates the 6502, in the form of the NES's Ricoh 2A03.
+//
+// A CPU is made with [New], runs on a [Bus] and is driven with [CPU.Step] or [CPU.Run]:
+//
+//	c := cpu.New(cpu.WithBus(bus))
+//	stop := c.Run(ctx, budget)
 package cpu
 
 import (
 	"fmt"
-	"math"
-)
-
-type Status struct {
-	N, V, P_, B, D, I, Z, C bool
-}
-
-const ( // status flag masks
-	posC byte = 1 << iota
-	posZ
-	posI
-	posD // decimal would go here but not supporting it
-	posB
-	pos_ // unused
-	posV
-	posN
+	"io"
 )
 
-func (status *Status) Clear() {
-	status.Set(0)
-}
-
-func (status *Status) Get() (b byte) {
-	if status.N {
-		b |= posN
-	}
-	if status.V {
-		b |= posV
-	}
-	if status.P_ {
-		b |= pos_
-	}
-	if status.B {
-		b |= posB
-	}
-	if status.D {
-		b |= posD
-	}
-	if status.I {
-		b |= posI
-	}
-	if status.Z {
-		b |= posZ
-	}
-	if status.C {
-		b |= posC
-	}
-	return b
-}
-
-func (status *Status) Set(b byte) {
-	status.N = (b&posN != 0)
-	status.V = (b&posV != 0)
-	status.P_ = (b&pos_ != 0)
-	status.B = (b&posB != 0)
-	status.D = (b&posD != 0)
-	status.I = (b&posI != 0)
-	status.Z = (b&posZ != 0)
-	status.C = (b&posC != 0)
-}
-
 type CPU struct {
 	pc      uint16
 	a, x, y byte
 	status  Status
 	// stack pointer. really uint16 but the high byte is always 0x01 so the effective addr is `0x01 | CPU.s`
 	s byte
-	// 0x0000-0x00FF is zero page (first page)
-	//
-	// 0x0100-0x01FF is system stack (second page)
-	//
-	// last 6 bytes 0xFFFA-0xFFFF must be programmed with the addresses of
-	// the non-maskable interrupt handler (0xFFFA/B),
-	// the power on reset location (0xFFFC/D)
-	// and the BRK/interrupt request handler (0xFFFE/F).
-	//
-	// https://www.nesdev.org/obelisk-6502-guide/architecture.html
-	memory  [0xFFFF + 1]byte
-	opcodes map[byte]opcode
+	// bus is what all memory accesses go through, and peeker is the same bus if it can peek.
+	bus    Bus
+	peeker Peeker
+	// opcodes is the dispatch table for [CPU.variant], indexed by opcode.
+	opcodes [256]opcode
+	// variant is the chip being emulated, which decides the opcode table and decimal mode.
+	variant Variant
+	// strict makes the CPU fault on any [opcode.Illegal] opcode instead of running it, to catch ROMs that depend on them.
+	strict bool
+	// magic is the "magic constant" the unstable ANE and LXA opcodes OR into the accumulator.
+	// It differs between chips (and even temperature) so it can be set to match the hardware being emulated.
+	magic byte
+	// halted is latched by a JAM opcode, or STP on the 65C02, and stops the CPU from running anything until [CPU.reset].
+	halted bool
+	// waiting is set by the 65C02's WAI, which stops the CPU until an interrupt line is asserted.
+	waiting bool
+	// accurate switches the run loop to the bus-cycle-accurate executor [CPU.stepAccurate].
+	accurate bool
+	// onCycle, if set, is called for each bus cycle of the accurate executor with the address, the data and whether it was a write.
+	onCycle func(addr uint16, dat byte, write bool)
+	// trace, if set, gets a line in the format of nestest.log before every instruction, see [CPU.TraceLine].
+	trace io.Writer
+	// tracePPU, if set, gives the PPU position for the trace instead of working it out from the cycles.
+	tracePPU func() (scanline, dot int)
+	// nmiLine is the level of the NMI input and nmiPending latches its edge until the NMI is serviced.
+	nmiLine, nmiPending bool
+	// irqLine is the level of the IRQ input.
+	irqLine bool
+	// pollI is the I flag as the last instruction's interrupt poll saw it.
+	// CLI, SEI and PLP set delayI because they change I after the poll, so it's the old value.
+	pollI, delayI bool
+	// pollNow and pollPrev are the interrupt samples the accurate executor takes on its last two cycles,
+	// and polled is the penultimate cycle's sample at the end of the instruction, which is the one the 6502 acts on.
+	pollNow, pollPrev, polled bool
+	// interrupted is set by an interrupt sequence, which doesn't poll, so the first instruction of the handler always runs.
+	// The accurate executor only needs it for BRK since it polls at the end of an instruction, see [CPU.interrupt].
+	interrupted bool
+	// cycles is the running count of cycles executed, which the rest of the NES is clocked off.
+	cycles uint64
+	// extraCycles is the number of cycles the current instruction took on top of its base [opcode.Cycles], e.g. for a taken branch or crossing a page.
+	extraCycles int
+	// breakpoints are the addresses [CPU.Run] stops at before executing the instruction there.
+	breakpoints map[uint16]bool
 }
 
 func newCPU() *CPU {
-	cpu := &CPU{}
+	cpu := &CPU{magic: defaultMagic}
+	cpu.SetBus(&RAM{})
 	cpu.initializeOpcodeTable()
 	return cpu
 }
@@ -96,58 +76,50 @@ func (cpu *CPU) String() string {
 	)
 }
 
-const (
-	implicit = iota // implied?
-	accumulator
-	immediate
-	zeroPage
-	zeroPageX
-	zeroPageY
-	relative
-	absolute
-	absoluteX
-	absoluteY
-	indirect
-	indirectX
-	indirectY
-)
-
-var modes = map[int]string{
-	implicit:    "implicit",
-	accumulator: "accumulator",
-	immediate:   "immediate",
-	zeroPage:    "zeroPage",
-	zeroPageX:   "zeroPageX",
-	zeroPageY:   "zeroPageY",
-	relative:    "relative",
-	absolute:    "absolute",
-	absoluteX:   "absoluteX",
-	absoluteY:   "absoluteY",
-	indirect:    "indirect",
-	indirectX:   "indirectX", // indexedIndirect, (Indirect,X)
-	indirectY:   "indirectY", // indirectIndexed, (Indirect),Y
-}
-
 // implementing 6502 as described in https://wdc65xx.com/Programming-Manual/ eyes/lichty
 // lots of the descriptions taken from https://www.nesdev.org/obelisk-6502-guide/reference.html
 
-// read returns the byte stored at the 16 bit position in memory
+// read returns the byte stored at the 16 bit position on the bus
 func (cpu *CPU) read(pos uint16) byte {
-	return cpu.memory[pos]
+	dat := cpu.bus.Read(pos)
+	if cpu.accurate {
+		cpu.tick(pos, dat, false)
+	}
+	return dat
 }
 
-// write stores the given byte `dat` into the 16 bit position in memory
+// write stores the given byte `dat` into the 16 bit position on the bus
 func (cpu *CPU) write(pos uint16, dat byte) {
-	cpu.memory[pos] = dat
+	cpu.bus.Write(pos, dat)
+	if cpu.accurate {
+		cpu.tick(pos, dat, true)
+	}
+}
+
+// peek returns the byte stored at pos like [CPU.read] but never counts as a bus cycle, for looking at memory from outside the CPU.
+// It uses the bus's [Peeker] if it has one so nothing on the bus sees it, otherwise it's a plain Read.
+func (cpu *CPU) peek(pos uint16) byte {
+	if cpu.peeker != nil {
+		return cpu.peeker.Peek(pos)
+	}
+	return cpu.bus.Read(pos)
 }
 
 func (cpu *CPU) read16(pos uint16) uint16 {
 
-	lo, hi := uint16(cpu.memory[pos]), uint16(cpu.memory[pos+1])
+	lo, hi := uint16(cpu.read(pos)), uint16(cpu.read(pos+1))
 	return hi<<8 | lo
 
 }
 
+// read16Wrap reads a uint16 like [CPU.read16] except the high byte is read from the same page as pos, so reading $xxFF gets the high byte from $xx00.
+//
+// This reproduces the NMOS bug in JMP ($xxFF) where the carry from the low byte of the pointer is never added to the high byte.
+func (cpu *CPU) read16Wrap(pos uint16) uint16 {
+	lo, hi := uint16(cpu.read(pos)), uint16(cpu.read(pos&0xFF00|uint16(byte(pos)+1)))
+	return hi<<8 | lo
+}
+
 func (cpu *CPU) write16(pos, dat uint16) {
 
 	cpu.write(pos, byte(dat))
@@ -177,32 +149,31 @@ func (cpu *CPU) pop16() uint16 {
 	return uint16(cpu.pop()) | uint16(cpu.pop())<<8 // low | hi << 8 // order sensitive
 }
 
+// pullStatus pops the processor status from the stack.
+//
+// The B flag and bit 5 only exist in the copy of the status that gets pushed so those two bits from the stack are ignored.
+func (cpu *CPU) pullStatus() {
+	kept := cpu.status.Get() & (posB | pos_)
+	cpu.status.Set(cpu.pop()&^(posB|pos_) | kept)
+}
+
 // effStack gets the effective stack pointer into memory by adding 0x0100 as the high byte to the supplied low byte stack pointer.
 func (cpu *CPU) effStack() uint16 {
 	return stackOffset | uint16(cpu.s)
 }
 
-const stackOffset = uint16(0x0100)
-const pcInitAddr = uint16(0xFFFC)
-const stackInit = byte(0xff - 2) // simulates stack pointer being at ff and "secretly" pushing PC and pushing P which increments p by 2 like BRK or IRQ
-
-func (cpu *CPU) reset() {
-	cpu.a, cpu.x, cpu.y = 0, 0, 0
-	cpu.status.Set(0x24) // clear and set B and I
-
-	cpu.pc = cpu.read16(pcInitAddr)
-	cpu.s = stackInit
+const defaultMagic = byte(0xEE) // a commonly observed value for ANE/LXA
 
-}
+const stackOffset = uint16(0x0100)
 
 // sets the zero flag if result was 0
 func (cpu *CPU) setZ(result byte) {
-	cpu.status.Z = result == 0
+	cpu.status.SetFlag(posZ, result == 0)
 }
 
 // The negative flag is set if the result of the last operation had bit 7 set to a one (negative 2s complement).
 func (cpu *CPU) setN(result byte) {
-	cpu.status.N = result&0x80 != 0
+	cpu.status.SetFlag(posN, result&0x80 != 0)
 }
 
 // setZN sets the zero flag [CPU.setZ] if result was 0 and the negative flag [CPU.setN] if the result of the last operation had bit 7 set to a one.
@@ -211,107 +182,259 @@ func (cpu *CPU) setZN(result byte) {
 	cpu.setN(result)
 }
 
-// deferrableSetFn makes a cpu.set_α function able to be called with the defer keyword and still work as expected.
+// addWithCarry adds val and the carry flag to the accumulator, setting the carry, overflow, zero and negative flags.
 //
-// It takes a setFn which accepts a byte and allows us to pass a POINTER to the byte
-// so the function can be deferred and not make a closure around the value of "result" prematurely.
+// The carry flag is set on unsigned overflow (the 9th bit of the sum).
+// The overflow flag is set on signed overflow, which happens when both operands have the same sign and the result's sign differs from it.
+// Decimal mode is ignored like it is on the NES's 2A03.
+func (cpu *CPU) addWithCarry(val byte) {
+	sum := uint16(cpu.a) + uint16(val)
+	if cpu.status.Flag(posC) {
+		sum += 1
+	}
+	result := byte(sum)
+
+	cpu.status.SetFlag(posC, sum > 0xFF)
+	cpu.status.SetFlag(posV, (cpu.a^result)&(val^result)&0x80 != 0)
+	cpu.a = result
+	cpu.setZN(cpu.a)
+}
+
+// compare sets the flags for reg-val without storing the result.
+// Carry is set if reg >= val, zero if they're equal and negative from bit 7 of the difference.
+func (cpu *CPU) compare(reg, val byte) {
+	cpu.status.SetFlag(posC, reg >= val)
+	cpu.setZN(reg - val)
+}
+
+// modify applies fn to the operand of a read-modify-write instruction and sets the zero and negative flags from the result.
 //
-// E.g. if we pass a value and the first line of a function is `defer setZN(cpu.a)` before we do anything and then set `cpu.a = 0`
-// the defer will have closed around the previous value of a and we won't properly set the zero flag.
-// Instead now we pass the pointer &cpu.a and when it runs the defer it will get the updated value of cpu.a = 0.
+// In accumulator mode the operand is [CPU.a], otherwise the byte in memory at dat.addr is read, modified and written back.
+func (cpu *CPU) modify(dat opDat, fn func(byte) byte) byte {
+	if dat.mode == accumulator {
+		cpu.a = fn(cpu.a)
+		cpu.setZN(cpu.a)
+		return cpu.a
+	}
+
+	val := cpu.read(dat.addr)
+	if cpu.accurate {
+		cpu.write(dat.addr, val) // the real chip writes the unmodified value back while it computes the result
+	}
+	result := fn(val)
+	cpu.write(dat.addr, result)
+	cpu.setZN(result)
+	return result
+}
+
+// shiftLeft shifts v one bit left, moving bit 7 into the carry flag.
+func (cpu *CPU) shiftLeft(v byte) byte {
+	cpu.status.SetFlag(posC, v&0x80 != 0)
+	return v << 1
+}
+
+// shiftRight shifts v one bit right, moving bit 0 into the carry flag.
+func (cpu *CPU) shiftRight(v byte) byte {
+	cpu.status.SetFlag(posC, v&0x01 != 0)
+	return v >> 1
+}
+
+// rotateLeft shifts v one bit left, filling bit 0 with the carry flag and moving bit 7 into the carry flag.
+func (cpu *CPU) rotateLeft(v byte) byte {
+	result := v << 1
+	if cpu.status.Flag(posC) {
+		result |= 0x01
+	}
+	cpu.status.SetFlag(posC, v&0x80 != 0)
+	return result
+}
+
+// rotateRight shifts v one bit right, filling bit 7 with the carry flag and moving bit 0 into the carry flag.
+func (cpu *CPU) rotateRight(v byte) byte {
+	result := v >> 1
+	if cpu.status.Flag(posC) {
+		result |= 0x80
+	}
+	cpu.status.SetFlag(posC, v&0x01 != 0)
+	return result
+}
+
+func increment(v byte) byte { return v + 1 }
+func decrement(v byte) byte { return v - 1 }
+
+// storeHigh is the store for the unstable SHA/SHX/SHY/TAS opcodes.
 //
-// TBD if this is a bad idea. Could also genericize.
-func deferrableSetFn(setFn func(byte)) func(*byte) {
-	return func(v *byte) { setFn(*v) }
+// They write val ANDed with the high byte of the unindexed base address plus one.
+// If adding the index crossed a page, the high byte of the target address is replaced by the value being written.
+func (cpu *CPU) storeHigh(dat opDat, val, index byte) {
+	base := dat.addr - uint16(index)
+	val &= byte(base>>8) + 1
+
+	addr := dat.addr
+	if base&0xFF00 != addr&0xFF00 {
+		addr = uint16(val)<<8 | addr&0x00FF
+	}
+	cpu.write(addr, val)
 }
 
-func (cpu *CPU) setB() {
-	cpu.status.B = true
+// branch sets the program counter to the relative target in dat.addr if cond is true.
+//
+// A taken branch costs one extra cycle, and another if the target is on a different page than the next instruction.
+func (cpu *CPU) branch(cond bool, dat opDat) {
+	if !cond {
+		return
+	}
+	cpu.extraCycles += 1
+	if pageCrossed(dat.pc, dat.addr) {
+		cpu.extraCycles += 1
+	}
+	cpu.pc = dat.addr
 }
 
 // brk - Force Interrupt
 //
 // The brk instruction forces the generation of an interrupt request.
-// The program counter and processor status are pushed on the stack then the IRQ interrupt vector at 0xFFFE/F is loaded into the PC and the break flag in the status set to one.
-func (cpu *CPU) brk(dat opDat) {
-	cpu.push16(cpu.pc)
-	cpu.setB()
-	cpu.php(dat)
-	// cpu.sei(dat) // TODO: ?
-	cpu.pc = cpu.read16(0xFFFE)
+// The program counter and processor status are pushed on the stack then the IRQ interrupt vector at 0xFFFE/F is loaded into the PC.
+// The copy of the status on the stack has the break flag set to tell it apart from an IRQ, and an NMI can hijack it like an IRQ, see [CPU.pushInterrupt].
+func (cpu *CPU) brk(opDat) {
+	cpu.pushInterrupt(true)
 }
 
 // lda - Load Accumulator
 //
 // load the value into register A and set Z and N flags if value is 0 or negative respectively.
 func (cpu *CPU) lda(dat opDat) {
-	defer deferrableSetFn(cpu.setZN)(&cpu.a)
 	cpu.a = cpu.read(dat.addr)
+	cpu.setZN(cpu.a)
 }
 
 // tax - Transfer Accumulator to X
 //
 // Copies the current contents of the accumulator into the X register and sets the zero and negative flags as appropriate. (transfer a to x)
 func (cpu *CPU) tax(opDat) {
-	defer deferrableSetFn(cpu.setZN)(&cpu.x)
-	// defer cpu.setZN(&cpu.x)
 	cpu.x = cpu.a
+	cpu.setZN(cpu.x)
 }
 
 // inx - Increment X Register
 //
 // Adds one to the X register setting the zero and negative flags as appropriate.
 func (cpu *CPU) inx(opDat) {
-	defer deferrableSetFn(cpu.setZN)(&cpu.x)
 	cpu.x += 1
+	cpu.setZN(cpu.x)
 }
 
-func (cpu *CPU) ora(opDat) {}
-func (cpu *CPU) jam(opDat) {}
-func (cpu *CPU) slo(opDat) {}
-func (cpu *CPU) nop(opDat) {}
-func (cpu *CPU) asl(opDat) {}
+// ora - Logical Inclusive OR
+//
+// A,Z,N = A|M
+//
+// An inclusive OR is performed, bit by bit, on the accumulator contents using the contents of a byte of memory.
+func (cpu *CPU) ora(dat opDat) {
+	cpu.a |= cpu.read(dat.addr)
+	cpu.setZN(cpu.a)
+}
+
+// jam - Halt the CPU (illegal)
+//
+// Also known as KIL or HLT. The CPU locks up with the program counter stuck on the JAM until it's reset.
+func (cpu *CPU) jam(dat opDat) {
+	cpu.pc = dat.pc - 1
+	cpu.halted = true
+}
+
+// slo - Shift Left then OR (illegal)
+//
+// M = M*2, A,Z,N = A|M
+//
+// ASL memory then ORA the result into the accumulator.
+func (cpu *CPU) slo(dat opDat) {
+	cpu.a |= cpu.modify(dat, cpu.shiftLeft)
+	cpu.setZN(cpu.a)
+}
+
+// nop - No Operation
+//
+// The NOP instruction causes no changes to the processor other than the normal incrementing of the program counter to the next instruction.
+//
+// The illegal multi byte NOPs still read their operand, which matters for memory mapped registers.
+func (cpu *CPU) nop(dat opDat) {
+	if dat.mode != implicit {
+		cpu.read(dat.addr)
+	}
+}
+
+// asl - Arithmetic Shift Left
+//
+// A,Z,C,N = M*2 or M,Z,C,N = M*2
+//
+// This operation shifts all the bits of the accumulator or memory contents one bit left.
+// Bit 0 is set to 0 and bit 7 is placed in the carry flag.
+func (cpu *CPU) asl(dat opDat) {
+	cpu.modify(dat, cpu.shiftLeft)
+}
 
 // php - Push Processor Status
 //
-// Pushes a copy of the status flags on to the stack.
+// Pushes a copy of the status flags on to the stack. Like BRK the copy always has the break flag and bit 5 set.
 func (cpu *CPU) php(opDat) {
-	cpu.push(cpu.status.Get())
+	cpu.push(cpu.status.Get() | posB | pos_)
+}
+
+// anc - AND then copy N to C (illegal)
+//
+// A,Z,N = A&M, C = N
+//
+// AND the immediate byte into the accumulator then copy bit 7 of the result into the carry flag.
+func (cpu *CPU) anc(dat opDat) {
+	cpu.and(dat)
+	cpu.status.SetFlag(posC, cpu.status.Flag(posN))
+}
+
+// bpl - Branch if Positive
+//
+// If the negative flag is clear then add the relative displacement to the program counter to cause a branch to a new location.
+func (cpu *CPU) bpl(dat opDat) {
+	cpu.branch(!cpu.status.Flag(posN), dat)
 }
-func (cpu *CPU) anc(opDat) {}
-func (cpu *CPU) bpl(opDat) {}
 
 // clc - Clear Carry Flag
 //
 // Set the carry flag to zero.
 func (cpu *CPU) clc(opDat) {
-	cpu.status.C = false
+	cpu.status.SetFlag(posC, false)
 }
 
 // cld - Clear Decimal Mode
 //
 // Sets the decimal mode flag to zero.
 //
-// NOTE: decimal mode will not be implemented
+// NOTE: the 2A03 has no decimal mode so there the flag is only stored, see [Variant2A03]
 func (cpu *CPU) cld(opDat) {
-	cpu.status.D = false
+	cpu.status.SetFlag(posD, false)
 }
 
 // cli - Clear Interrupt Disable
 //
 // Clears the interrupt disable flag allowing normal interrupt requests to be serviced.
 func (cpu *CPU) cli(opDat) {
-	cpu.status.I = false
+	cpu.status.SetFlag(posI, false)
+	cpu.delayI = true
 }
 
 // clv - Clear Overflow Flag
 //
 // Clears the overflow flag.
 func (cpu *CPU) clv(opDat) {
-	cpu.status.V = false
+	cpu.status.SetFlag(posV, false)
 }
 
-func (cpu *CPU) jsr(opDat) {}
+// jsr - Jump to Subroutine
+//
+// The JSR instruction pushes the address (minus one) of the return point on to the stack and then sets the program counter to the target memory address.
+func (cpu *CPU) jsr(dat opDat) {
+	cpu.push16(dat.pc - 1) // dat.pc is the next instruction so minus one is the last byte of the JSR
+	cpu.pc = dat.addr
+}
 
 // and - Logical AND
 //
@@ -319,30 +442,222 @@ func (cpu *CPU) jsr(opDat) {}
 //
 // A logical AND is performed, bit by bit, on the accumulator contents using the contents of a byte of memory.
 func (cpu *CPU) and(dat opDat) {
-	defer deferrableSetFn(cpu.setZN)(&cpu.a)
 	cpu.a &= cpu.read(dat.addr)
+	cpu.setZN(cpu.a)
+}
+
+// rla - Rotate Left then AND (illegal)
+//
+// M = M ROL 1, A,Z,N = A&M
+//
+// ROL memory then AND the result into the accumulator.
+func (cpu *CPU) rla(dat opDat) {
+	cpu.a &= cpu.modify(dat, cpu.rotateLeft)
+	cpu.setZN(cpu.a)
+}
+
+// bit - Bit Test
+//
+// A & M, N = M7, V = M6
+//
+// This instructions is used to test if one or more bits are set in a target memory location.
+// The mask pattern in A is ANDed with the value in memory to set or clear the zero flag, but the result is not kept.
+// Bits 7 and 6 of the value from memory are copied into the N and V flags.
+func (cpu *CPU) bit(dat opDat) {
+	val := cpu.read(dat.addr)
+	cpu.setZ(cpu.a & val)
+	if dat.mode == immediate {
+		return // the 65C02's BIT # only sets Z
+	}
+	cpu.setN(val)
+	cpu.status.SetFlag(posV, val&0x40 != 0)
+}
+
+// rol - Rotate Left
+//
+// Move each of the bits in either A or M one place to the left.
+// Bit 0 is filled with the current value of the carry flag whilst the old bit 7 becomes the new carry flag value.
+func (cpu *CPU) rol(dat opDat) {
+	cpu.modify(dat, cpu.rotateLeft)
+}
+
+// plp - Pull Processor Status
+//
+// Pulls an 8 bit value from the stack and into the processor flags. The flags will take on new states as determined by the value pulled.
+func (cpu *CPU) plp(opDat) {
+	cpu.pullStatus()
+	cpu.delayI = true
+}
+
+// bmi - Branch if Minus
+//
+// If the negative flag is set then add the relative displacement to the program counter to cause a branch to a new location.
+func (cpu *CPU) bmi(dat opDat) {
+	cpu.branch(cpu.status.Flag(posN), dat)
+}
+
+// sec - Set Carry Flag
+//
+// C = 1
+//
+// Set the carry flag to one.
+func (cpu *CPU) sec(opDat) {
+	cpu.status.SetFlag(posC, true)
+}
+
+// rti - Return from Interrupt
+//
+// The RTI instruction is used at the end of an interrupt processing routine.
+// It pulls the processor flags from the stack followed by the program counter.
+func (cpu *CPU) rti(opDat) {
+	cpu.pullStatus()
+	cpu.pc = cpu.pop16()
+}
+
+// eor - Exclusive OR
+//
+// A,Z,N = A^M
+//
+// An exclusive OR is performed, bit by bit, on the accumulator contents using the contents of a byte of memory.
+func (cpu *CPU) eor(dat opDat) {
+	cpu.a ^= cpu.read(dat.addr)
+	cpu.setZN(cpu.a)
+}
+
+// sre - Shift Right then EOR (illegal)
+//
+// M = M/2, A,Z,N = A^M
+//
+// LSR memory then EOR the result into the accumulator.
+func (cpu *CPU) sre(dat opDat) {
+	cpu.a ^= cpu.modify(dat, cpu.shiftRight)
+	cpu.setZN(cpu.a)
+}
+
+// lsr - Logical Shift Right
+//
+// A,C,Z,N = A/2 or M,C,Z,N = M/2
+//
+// Each of the bits in A or M is shift one place to the right.
+// The bit that was in bit 0 is shifted into the carry flag. Bit 7 is set to zero.
+func (cpu *CPU) lsr(dat opDat) {
+	cpu.modify(dat, cpu.shiftRight)
+}
+
+// pha - Push Accumulator
+//
+// Pushes a copy of the accumulator on to the stack.
+func (cpu *CPU) pha(opDat) {
+	cpu.push(cpu.a)
+}
+
+// alr - AND then Logical Shift Right (illegal)
+//
+// A = (A&M)/2
+//
+// AND the immediate byte into the accumulator then LSR the accumulator.
+func (cpu *CPU) alr(dat opDat) {
+	cpu.a = cpu.shiftRight(cpu.a & cpu.read(dat.addr))
+	cpu.setZN(cpu.a)
+}
+
+// jmp - Jump
+//
+// Sets the program counter to the address specified by the operand.
+//
+// The indirect mode's NMOS page wrap bug is handled when resolving the address, see [CPU.read16Wrap].
+func (cpu *CPU) jmp(dat opDat) {
+	cpu.pc = dat.addr
+}
+
+// bvc - Branch if Overflow Clear
+//
+// If the overflow flag is clear then add the relative displacement to the program counter to cause a branch to a new location.
+func (cpu *CPU) bvc(dat opDat) {
+	cpu.branch(!cpu.status.Flag(posV), dat)
+}
+
+// rts - Return from Subroutine
+//
+// The RTS instruction is used at the end of a subroutine to return to the calling routine.
+// It pulls the program counter (minus one) from the stack.
+func (cpu *CPU) rts(opDat) {
+	cpu.pc = cpu.pop16() + 1
+}
+
+// adc - Add with Carry
+//
+// A,Z,C,N = A+M+C
+//
+// This instruction adds the contents of a memory location to the accumulator together with the carry bit.
+// If overflow occurs the carry bit is set, this enables multiple byte addition to be performed.
+// In decimal mode the operands are BCD, see [CPU.addDecimal].
+func (cpu *CPU) adc(dat opDat) {
+	cpu.add(cpu.read(dat.addr))
+}
+
+// rra - Rotate Right then Add with Carry (illegal)
+//
+// M = M ROR 1, A,Z,C,V,N = A+M+C
+//
+// ROR memory then ADC the result into the accumulator, using the carry that was rotated out.
+func (cpu *CPU) rra(dat opDat) {
+	cpu.add(cpu.modify(dat, cpu.rotateRight))
+}
+
+// ror - Rotate Right
+//
+// Move each of the bits in either A or M one place to the right.
+// Bit 7 is filled with the current value of the carry flag whilst the old bit 0 becomes the new carry flag value.
+func (cpu *CPU) ror(dat opDat) {
+	cpu.modify(dat, cpu.rotateRight)
+}
+
+// pla - Pull Accumulator
+//
+// Pulls an 8 bit value from the stack and into the accumulator. The zero and negative flags are set as appropriate.
+func (cpu *CPU) pla(opDat) {
+	cpu.a = cpu.pop()
+	cpu.setZN(cpu.a)
+}
+
+// arr - AND then Rotate Right (illegal)
+//
+// A = (A&M) ROR 1
+//
+// AND the immediate byte into the accumulator then ROR the accumulator, except the carry and overflow flags come from the adder:
+// C is bit 6 of the result and V is bit 6 XOR bit 5 of the result.
+//
+// In decimal mode the adder also does a BCD fixup on each digit of the AND, after the flags are set from the rotate.
+// http://www.oxyron.de/html/opcodes02.html
+func (cpu *CPU) arr(dat opDat) {
+	cpu.a &= cpu.read(dat.addr)
+	if cpu.decimal() {
+		and := cpu.a
+		cpu.a = cpu.rotateRight(cpu.a)
+		cpu.setZN(cpu.a)
+		cpu.status.SetFlag(posV, (and^cpu.a)&0x40 != 0)
+		if and&0x0F+and&0x01 > 0x05 {
+			cpu.a = cpu.a&0xF0 | (cpu.a+0x06)&0x0F
+		}
+		cpu.status.SetFlag(posC, int(and>>4)+int(and>>4&0x01) > 0x05)
+		if cpu.status.Flag(posC) {
+			cpu.a += 0x60
+		}
+		return
+	}
+	cpu.a = cpu.rotateRight(cpu.a)
+	cpu.setZN(cpu.a)
+	cpu.status.SetFlag(posC, cpu.a&0x40 != 0)
+	cpu.status.SetFlag(posV, (cpu.a>>6^cpu.a>>5)&0x01 != 0)
+}
+
+// bvs - Branch if Overflow Set
+//
+// If the overflow flag is set then add the relative displacement to the program counter to cause a branch to a new location.
+func (cpu *CPU) bvs(dat opDat) {
+	cpu.branch(cpu.status.Flag(posV), dat)
 }
-func (cpu *CPU) rla(opDat) {}
-func (cpu *CPU) bit(opDat) {}
-func (cpu *CPU) rol(opDat) {}
-func (cpu *CPU) plp(opDat) {}
-func (cpu *CPU) bmi(opDat) {}
-func (cpu *CPU) sec(opDat) {}
-func (cpu *CPU) rti(opDat) {}
-func (cpu *CPU) eor(opDat) {}
-func (cpu *CPU) sre(opDat) {}
-func (cpu *CPU) lsr(opDat) {}
-func (cpu *CPU) pha(opDat) {}
-func (cpu *CPU) alr(opDat) {}
-func (cpu *CPU) jmp(opDat) {}
-func (cpu *CPU) bvc(opDat) {}
-func (cpu *CPU) rts(opDat) {}
-func (cpu *CPU) adc(opDat) {}
-func (cpu *CPU) rra(opDat) {}
-func (cpu *CPU) ror(opDat) {}
-func (cpu *CPU) pla(opDat) {}
-func (cpu *CPU) arr(opDat) {}
-func (cpu *CPU) bvs(opDat) {}
 
 // SEI - Set Interrupt Disable
 //
@@ -350,103 +665,411 @@ func (cpu *CPU) bvs(opDat) {}
 //
 // Set the interrupt disable flag to one.
 func (cpu *CPU) sei(opDat) {
-	cpu.status.I = true
-}
-func (cpu *CPU) sta(opDat)  {}
-func (cpu *CPU) sax(opDat)  {}
-func (cpu *CPU) sty(opDat)  {}
-func (cpu *CPU) stx(opDat)  {}
-func (cpu *CPU) dey(opDat)  {}
-func (cpu *CPU) txa(opDat)  {}
-func (cpu *CPU) ane(opDat)  {}
-func (cpu *CPU) bcc(opDat)  {}
-func (cpu *CPU) sha(opDat)  {}
-func (cpu *CPU) tya(opDat)  {}
-func (cpu *CPU) txs(opDat)  {}
-func (cpu *CPU) tas(opDat)  {}
-func (cpu *CPU) shy(opDat)  {}
-func (cpu *CPU) shx(opDat)  {}
-func (cpu *CPU) ldy(opDat)  {}
-func (cpu *CPU) ldx(opDat)  {}
-func (cpu *CPU) lax(opDat)  {}
-func (cpu *CPU) tay(opDat)  {}
-func (cpu *CPU) lxa(opDat)  {}
-func (cpu *CPU) bcs(opDat)  {}
-func (cpu *CPU) tsx(opDat)  {}
-func (cpu *CPU) las(opDat)  {}
-func (cpu *CPU) cpy(opDat)  {}
-func (cpu *CPU) cmp(opDat)  {}
-func (cpu *CPU) dcp(opDat)  {}
-func (cpu *CPU) dec(opDat)  {}
-func (cpu *CPU) iny(opDat)  {}
-func (cpu *CPU) dex(opDat)  {}
-func (cpu *CPU) sbx(opDat)  {}
-func (cpu *CPU) bne(opDat)  {}
-func (cpu *CPU) cpx(opDat)  {}
-func (cpu *CPU) sbc(opDat)  {}
-func (cpu *CPU) isc(opDat)  {}
-func (cpu *CPU) inc(opDat)  {}
-func (cpu *CPU) usbc(opDat) {}
-func (cpu *CPU) beq(opDat)  {}
-func (cpu *CPU) sed(opDat)  {}
+	cpu.status.SetFlag(posI, true)
+	cpu.delayI = true
+}
+
+// sta - Store Accumulator
+//
+// M = A
+//
+// Stores the contents of the accumulator into memory.
+func (cpu *CPU) sta(dat opDat) {
+	cpu.write(dat.addr, cpu.a)
+}
+
+// sax - Store A AND X (illegal)
+//
+// M = A&X
+//
+// Stores the bitwise AND of the accumulator and X register into memory. No flags are affected.
+func (cpu *CPU) sax(dat opDat) {
+	cpu.write(dat.addr, cpu.a&cpu.x)
+}
+
+// sty - Store Y Register
+//
+// M = Y
+//
+// Stores the contents of the Y register into memory.
+func (cpu *CPU) sty(dat opDat) {
+	cpu.write(dat.addr, cpu.y)
+}
+
+// stx - Store X Register
+//
+// M = X
+//
+// Stores the contents of the X register into memory.
+func (cpu *CPU) stx(dat opDat) {
+	cpu.write(dat.addr, cpu.x)
+}
+
+// dey - Decrement Y Register
+//
+// Y,Z,N = Y-1
+//
+// Subtracts one from the Y register setting the zero and negative flags as appropriate.
+func (cpu *CPU) dey(opDat) {
+	cpu.y -= 1
+	cpu.setZN(cpu.y)
+}
+
+// txa - Transfer X to Accumulator
+//
+// A = X
+//
+// Copies the current contents of the X register into the accumulator and sets the zero and negative flags as appropriate.
+func (cpu *CPU) txa(opDat) {
+	cpu.a = cpu.x
+	cpu.setZN(cpu.a)
+}
+
+// ane - OR magic, AND X, AND immediate (illegal, unstable)
+//
+// A,Z,N = (A|magic)&X&M
+//
+// The real chip ORs the accumulator with a "magic constant" that varies between chips and with temperature, see [CPU.magic].
+func (cpu *CPU) ane(dat opDat) {
+	cpu.a = (cpu.a | cpu.magic) & cpu.x & cpu.read(dat.addr)
+	cpu.setZN(cpu.a)
+}
+
+// bcc - Branch if Carry Clear
+//
+// If the carry flag is clear then add the relative displacement to the program counter to cause a branch to a new location.
+func (cpu *CPU) bcc(dat opDat) {
+	cpu.branch(!cpu.status.Flag(posC), dat)
+}
+
+// sha - Store A AND X AND High byte (illegal, unstable)
+//
+// M = A&X&(H+1)
+//
+// Stores the accumulator ANDed with the X register and the high byte of the base address plus one, see [CPU.storeHigh].
+func (cpu *CPU) sha(dat opDat) {
+	cpu.storeHigh(dat, cpu.a&cpu.x, cpu.y)
+}
+
+// tya - Transfer Y to Accumulator
+//
+// A = Y
+//
+// Copies the current contents of the Y register into the accumulator and sets the zero and negative flags as appropriate.
+func (cpu *CPU) tya(opDat) {
+	cpu.a = cpu.y
+	cpu.setZN(cpu.a)
+}
+
+// txs - Transfer X to Stack Pointer
+//
+// S = X
+//
+// Copies the current contents of the X register into the stack register. No flags are affected.
+func (cpu *CPU) txs(opDat) {
+	cpu.s = cpu.x
+}
+
+// tas - Transfer A AND X to S, then store like SHA (illegal, unstable)
+//
+// S = A&X, M = S&(H+1)
+//
+// Sets the stack pointer to the accumulator ANDed with the X register, then stores that ANDed with the high byte of the base address plus one, see [CPU.storeHigh].
+func (cpu *CPU) tas(dat opDat) {
+	cpu.s = cpu.a & cpu.x
+	cpu.storeHigh(dat, cpu.s, cpu.y)
+}
+
+// shy - Store Y AND High byte (illegal, unstable)
+//
+// M = Y&(H+1)
+//
+// Stores the Y register ANDed with the high byte of the base address plus one, see [CPU.storeHigh].
+func (cpu *CPU) shy(dat opDat) {
+	cpu.storeHigh(dat, cpu.y, cpu.x)
+}
+
+// shx - Store X AND High byte (illegal, unstable)
+//
+// M = X&(H+1)
+//
+// Stores the X register ANDed with the high byte of the base address plus one, see [CPU.storeHigh].
+func (cpu *CPU) shx(dat opDat) {
+	cpu.storeHigh(dat, cpu.x, cpu.y)
+}
+
+// ldy - Load Y Register
+//
+// Y,Z,N = M
+//
+// Loads a byte of memory into the Y register setting the zero and negative flags as appropriate.
+func (cpu *CPU) ldy(dat opDat) {
+	cpu.y = cpu.read(dat.addr)
+	cpu.setZN(cpu.y)
+}
+
+// ldx - Load X Register
+//
+// X,Z,N = M
+//
+// Loads a byte of memory into the X register setting the zero and negative flags as appropriate.
+func (cpu *CPU) ldx(dat opDat) {
+	cpu.x = cpu.read(dat.addr)
+	cpu.setZN(cpu.x)
+}
+
+// lax - Load A and X (illegal)
+//
+// A,X,Z,N = M
+//
+// Loads a byte of memory into both the accumulator and the X register setting the zero and negative flags as appropriate.
+func (cpu *CPU) lax(dat opDat) {
+	cpu.a = cpu.read(dat.addr)
+	cpu.x = cpu.a
+	cpu.setZN(cpu.a)
+}
+
+// tay - Transfer Accumulator to Y
+//
+// Y = A
+//
+// Copies the current contents of the accumulator into the Y register and sets the zero and negative flags as appropriate.
+func (cpu *CPU) tay(opDat) {
+	cpu.y = cpu.a
+	cpu.setZN(cpu.y)
+}
+
+// lxa - OR magic, AND immediate, load A and X (illegal, unstable)
+//
+// A,X,Z,N = (A|magic)&M
+//
+// Like [CPU.ane] the real chip ORs the accumulator with an unreliable "magic constant", see [CPU.magic].
+func (cpu *CPU) lxa(dat opDat) {
+	cpu.a = (cpu.a | cpu.magic) & cpu.read(dat.addr)
+	cpu.x = cpu.a
+	cpu.setZN(cpu.a)
+}
+
+// bcs - Branch if Carry Set
+//
+// If the carry flag is set then add the relative displacement to the program counter to cause a branch to a new location.
+func (cpu *CPU) bcs(dat opDat) {
+	cpu.branch(cpu.status.Flag(posC), dat)
+}
+
+// tsx - Transfer Stack Pointer to X
+//
+// X = S
+//
+// Copies the current contents of the stack register into the X register and sets the zero and negative flags as appropriate.
+func (cpu *CPU) tsx(opDat) {
+	cpu.x = cpu.s
+	cpu.setZN(cpu.x)
+}
+
+// las - Load A, X and S with memory AND S (illegal)
+//
+// A,X,S,Z,N = M&S
+func (cpu *CPU) las(dat opDat) {
+	cpu.s &= cpu.read(dat.addr)
+	cpu.a, cpu.x = cpu.s, cpu.s
+	cpu.setZN(cpu.a)
+}
+
+// cpy - Compare Y Register
+//
+// Z,C,N = Y-M
+//
+// This instruction compares the contents of the Y register with another memory held value and sets the zero and carry flags as appropriate.
+func (cpu *CPU) cpy(dat opDat) {
+	cpu.compare(cpu.y, cpu.read(dat.addr))
+}
+
+// cmp - Compare
+//
+// Z,C,N = A-M
+//
+// This instruction compares the contents of the accumulator with another memory held value and sets the zero and carry flags as appropriate.
+func (cpu *CPU) cmp(dat opDat) {
+	cpu.compare(cpu.a, cpu.read(dat.addr))
+}
+
+// dcp - Decrement then Compare (illegal)
+//
+// M = M-1, Z,C,N = A-M
+//
+// DEC memory then CMP the result with the accumulator.
+func (cpu *CPU) dcp(dat opDat) {
+	cpu.compare(cpu.a, cpu.modify(dat, decrement))
+}
+
+// dec - Decrement Memory
+//
+// M,Z,N = M-1
+//
+// Subtracts one from the value held at a specified memory location setting the zero and negative flags as appropriate.
+func (cpu *CPU) dec(dat opDat) {
+	cpu.modify(dat, decrement)
+}
+
+// iny - Increment Y Register
+//
+// Y,Z,N = Y+1
+//
+// Adds one to the Y register setting the zero and negative flags as appropriate.
+func (cpu *CPU) iny(opDat) {
+	cpu.y += 1
+	cpu.setZN(cpu.y)
+}
+
+// dex - Decrement X Register
+//
+// X,Z,N = X-1
+//
+// Subtracts one from the X register setting the zero and negative flags as appropriate.
+func (cpu *CPU) dex(opDat) {
+	cpu.x -= 1
+	cpu.setZN(cpu.x)
+}
+
+// sbx - Subtract from A AND X into X (illegal)
+//
+// X,Z,C,N = (A&X)-M
+//
+// Subtracts the immediate byte from the accumulator ANDed with the X register without borrow, setting the flags like [CPU.compare].
+func (cpu *CPU) sbx(dat opDat) {
+	val := cpu.read(dat.addr)
+	cpu.compare(cpu.a&cpu.x, val)
+	cpu.x = cpu.a&cpu.x - val
+}
+
+// bne - Branch if Not Equal
+//
+// If the zero flag is clear then add the relative displacement to the program counter to cause a branch to a new location.
+func (cpu *CPU) bne(dat opDat) {
+	cpu.branch(!cpu.status.Flag(posZ), dat)
+}
+
+// cpx - Compare X Register
+//
+// Z,C,N = X-M
+//
+// This instruction compares the contents of the X register with another memory held value and sets the zero and carry flags as appropriate.
+func (cpu *CPU) cpx(dat opDat) {
+	cpu.compare(cpu.x, cpu.read(dat.addr))
+}
+
+// sbc - Subtract with Carry
+//
+// A,Z,C,N = A-M-(1-C)
+//
+// This instruction subtracts the contents of a memory location to the accumulator together with the not of the carry bit.
+// If overflow occurs the carry bit is clear, this enables multiple byte subtraction to be performed.
+// In decimal mode the operands are BCD, see [CPU.subtractDecimal].
+func (cpu *CPU) sbc(dat opDat) {
+	cpu.subtract(cpu.read(dat.addr))
+}
+
+// isc - Increment then Subtract with Carry (illegal)
+//
+// M = M+1, A,Z,C,V,N = A-M-(1-C)
+//
+// INC memory then SBC the result from the accumulator.
+func (cpu *CPU) isc(dat opDat) {
+	cpu.subtract(cpu.modify(dat, increment))
+}
+
+// inc - Increment Memory
+//
+// M,Z,N = M+1
+//
+// Adds one to the value held at a specified memory location setting the zero and negative flags as appropriate.
+func (cpu *CPU) inc(dat opDat) {
+	cpu.modify(dat, increment)
+}
+
+// usbc - the illegal 0xEB copy of SBC immediate. Behaves exactly like [CPU.sbc].
+func (cpu *CPU) usbc(dat opDat) {
+	cpu.sbc(dat)
+}
+
+// beq - Branch if Equal
+//
+// If the zero flag is set then add the relative displacement to the program counter to cause a branch to a new location.
+func (cpu *CPU) beq(dat opDat) {
+	cpu.branch(cpu.status.Flag(posZ), dat)
+}
+
+// sed - Set Decimal Flag
+//
+// D = 1
+//
+// Set the decimal mode flag to one.
+//
+// NOTE: the 2A03 has no decimal mode so there the flag is only stored, see [Variant2A03]
+func (cpu *CPU) sed(opDat) {
+	cpu.status.SetFlag(posD, true)
+}
 
 // 4 + 72 = 76
 // 23 illegals
 
-func (cpu *CPU) Hotloop(program []byte) {
-	if len(program) > math.MaxUint16 {
-		panic(fmt.Errorf("len of program %v greater than max %v", len(program), math.MaxUint16))
-	}
+// IllegalOpcodeError is the fault raised when a CPU in strict mode fetches an illegal opcode.
+type IllegalOpcodeError struct {
+	PC     uint16
+	Opcode byte
+	Name   string
+}
+
+func (e *IllegalOpcodeError) Error() string {
+	return fmt.Sprintf("illegal opcode 0x%02x (%v) at 0x%04x", e.Opcode, e.Name, e.PC)
+}
 
-	copy(cpu.memory[:], program) // TODO: actually load
-
-	for !cpu.status.B {
-		op, nPC := cpu.opcodes[cpu.read(cpu.pc)], cpu.pc+1
-		dat := opDat{mode: op.Mode}
-		switch op.Mode {
-		case implicit:
-		case accumulator:
-		case immediate:
-			dat.addr = nPC
-		case zeroPage:
-			dat.addr = uint16(cpu.read(nPC))
-		case zeroPageX:
-			dat.addr = uint16(cpu.read(nPC) + cpu.x)
-		case zeroPageY: // This mode can only be used with the LDX and STX instructions.
-			dat.addr = uint16(cpu.read(nPC) + cpu.y)
-		case relative:
-			dat.addr = nPC + 1 + uint16(int8(cpu.read(nPC))) // the "byte" read is really a signed int8. Interpret as int8 then cast to unsigned 2s complement and account for the instruction length.
-		case absolute:
-			dat.addr = cpu.read16(nPC)
-		case absoluteX:
-			dat.addr = cpu.read16(nPC) + uint16(cpu.x)
-		case absoluteY:
-			dat.addr = cpu.read16(nPC) + uint16(cpu.y)
-		// JMP is the only 6502 instruction to support indirection.
-		// The instruction contains a 16 bit address which identifies the location of the least significant byte of another 16 bit memory address which is the real target of the instruction.
-		case indirect:
-			dat.addr = cpu.read16(cpu.read16(nPC)) // TODO: apparently there's a bug ?
-		// Indexed indirect addressing is normally used in conjunction with a table of address held on zero page.
-		// The address of the table is taken from the instruction and the X register added to it (with zero page wrap around) to give the location of the least significant byte of the target address.
-		case indirectX:
-			dat.addr = cpu.read16(
-				uint16(cpu.read(nPC)) + uint16(cpu.x),
-			)
-		// Indirect indirect addressing is the most common indirection mode used on the 6502.
-		// In instruction contains the zero page location of the least significant byte of 16 bit address. The Y register is dynamically added to this value to generated the actual target address for operation.
-		case indirectY:
-			dat.addr = cpu.read16(
-				uint16(cpu.read(nPC)),
-			) + uint16(cpu.y)
-		default:
-			panic(fmt.Errorf("unknown mode for op: %+v, cpu: %+v", op, cpu))
+// step executes the instruction at [CPU.pc], or the interrupt sequence if an interrupt was polled, and returns what it ran.
+// The cycles it took are also added to [CPU.cycles].
+//
+// In strict mode an illegal opcode isn't run and the error is an [*IllegalOpcodeError].
+func (cpu *CPU) step() (StepInfo, error) {
+	if cpu.waiting {
+		if !cpu.nmiPending && !cpu.irqLine {
+			cpu.cycles += 1
+			return StepInfo{PC: cpu.pc - 1, Opcode: cpu.peek(cpu.pc - 1), Name: "WAI", Cycles: 1}, nil
 		}
-		cpu.pc += op.Size
-		// TODO: count cycles and page crossings
-		dat.pc = cpu.pc
+		cpu.waiting = false
+	}
+	// the lines as they are now count as sampled by the last instruction's poll
+	if !cpu.interrupted && (cpu.nmiPending || cpu.irqLine && !cpu.pollI) {
+		return cpu.interrupt(), nil
+	}
+	cpu.interrupted = false
+	if cpu.trace != nil {
+		cpu.traceInstruction()
+	}
 
-		op.Do(dat)
+	opc := cpu.read(cpu.pc)
+	op, nPC := &cpu.opcodes[opc], cpu.pc+1
+	info := StepInfo{PC: cpu.pc, Opcode: opc, Name: op.Name}
+	if op.Illegal && cpu.strict {
+		return info, &IllegalOpcodeError{PC: cpu.pc, Opcode: opc, Name: op.Name}
+	}
+	dat, crossed := cpu.address(op, nPC)
+	cpu.pc += op.Size
+	dat.pc = cpu.pc
 
+	cpu.extraCycles = 0
+	if crossed && op.PageCross {
+		cpu.extraCycles += 1
 	}
-	fmt.Printf("Time to take a BRK. bye :)\n")
+	iBefore := cpu.status.Flag(posI)
+	cpu.delayI = false
+	op.Do(dat)
+	cpu.pollI = cpu.status.Flag(posI)
+	if cpu.delayI {
+		cpu.pollI = iBefore
+	}
+
+	info.Addr = dat.addr
+	info.Cycles = op.Cycles + cpu.extraCycles
+	cpu.cycles += uint64(info.Cycles)
+	return info, nil
 }
@@ -1,12 +1,30 @@
 package cpu
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
 
+// runProgram loads program at address 0 and runs it until it BRKs into the handler at the IRQ vector.
+func runProgram(cpu *CPU, program []byte) Stop {
+	copy(cpu.ram()[:], program)
+	cpu.SetBreakpoint(uint16(cpu.peek(irqVector+1))<<8 | uint16(cpu.peek(irqVector)))
+	return cpu.Run(context.Background(), 1_000_000) // the budget keeps a test that never BRKs from hanging
+}
+
+// ram is the flat [RAM] a test cpu from [newCPU] runs on.
+func (cpu *CPU) ram() *RAM {
+	return cpu.bus.(*RAM)
+}
+
+// stepCycles returns the cycles from the results of [CPU.Step].
+func stepCycles(info StepInfo, _ error) int {
+	return info.Cycles
+}
+
 func TestBitBang(t *testing.T) {
 	Convey("Bit bangs", t, func() {
 		c := CPU{}
@@ -97,14 +115,14 @@ func TestOpcodes(t *testing.T) {
 		Convey("should load test value with LDA and BRK", func() {
 			val := byte(0x69)
 
-			cpu.Hotloop([]byte{0xa9, val, 0x00, 0x00}) // LDA, val, BRK, ignored brk value
+			runProgram(cpu, []byte{0xa9, val, 0x00, 0x00}) // LDA, val, BRK, ignored brk value
 			So(cpu.a, ShouldEqual, val)
 
 			// pc gets set to the val at 0xFFFE after brk (IRQ interrupt vector)
 			So(cpu.pc, ShouldEqual, 0x1234)
 
 			oldStatus, oldPC := cpu.pop(), cpu.pop16() // get the status and program counter from the stack
-			So(oldStatus, ShouldEqual, 0x00|posB)
+			So(oldStatus, ShouldEqual, posB|pos_)      // bit 5 is always set in the pushed copy
 			So(oldPC, ShouldEqual, 4)
 
 		})
@@ -113,14 +131,14 @@ func TestOpcodes(t *testing.T) {
 			val := byte(0x42)
 
 			cpu.a = val
-			cpu.Hotloop([]byte{0xaa, 0x00, 0x00}) // TAX, BRK, ignored brk val
+			runProgram(cpu, []byte{0xaa, 0x00, 0x00}) // TAX, BRK, ignored brk val
 
 			So(cpu.x, ShouldEqual, val)
 
 			So(cpu.pc, ShouldEqual, 0x1234)
 
 			oldStatus, oldPC := cpu.pop(), cpu.pop16() // get the status and program counter from the stack
-			So(oldStatus, ShouldEqual, 0x00|posB)
+			So(oldStatus, ShouldEqual, posB|pos_)      // bit 5 is always set in the pushed copy
 			So(oldPC, ShouldEqual, 3)
 		})
 
@@ -130,7 +148,7 @@ func TestOpcodes(t *testing.T) {
 				val := byte(0x69)
 				cpu.x = val
 
-				cpu.Hotloop([]byte{0xe8, 0x00})
+				runProgram(cpu, []byte{0xe8, 0x00})
 
 				So(cpu.x, ShouldEqual, val+1)
 				So(cpu.status.Get()&posZ, ShouldEqual, 0)
@@ -140,7 +158,7 @@ func TestOpcodes(t *testing.T) {
 			Convey("test neg 1 to zero", func() {
 				val := byte(0xff) // -1
 				cpu.x = val
-				cpu.Hotloop([]byte{0xe8, 0x00})
+				runProgram(cpu, []byte{0xe8, 0x00})
 
 				So(cpu.x, ShouldEqual, val+1)
 				So(cpu.status.Get()&posZ, ShouldNotEqual, 0)
@@ -161,17 +179,17 @@ func TestOpcodes(t *testing.T) {
 		Convey("simple programs", func() {
 
 			Convey("p1", func() {
-				cpu.Hotloop([]byte{0xa9, 0xc0, 0xaa, 0xe8, 0x00})
+				runProgram(cpu, []byte{0xa9, 0xc0, 0xaa, 0xe8, 0x00})
 				So(cpu.x, ShouldEqual, 0xc1)
 
 				oldStatus, oldPC := cpu.pop(), cpu.pop16() // get the status and program counter from the stack
-				So(oldStatus, ShouldEqual, 0x00|posB|posN)
+				So(oldStatus, ShouldEqual, posB|pos_|posN) // bit 5 is always set in the pushed copy
 				So(oldPC, ShouldEqual, 6)
 			})
 
 			Convey("p2", func() {
 				cpu.x = 0xff
-				cpu.Hotloop([]byte{0xe8, 0xe8, 0x00})
+				runProgram(cpu, []byte{0xe8, 0xe8, 0x00})
 				So(cpu.x, ShouldEqual, 1)
 			})
 
@@ -183,14 +201,14 @@ func TestOpcodes(t *testing.T) {
 
 func TestMemory(t *testing.T) {
 	Convey("should test memory", t, func() {
-		cpu := CPU{}
+		cpu := newCPU()
 
 		Convey("test read and write", func() {
 			// write 16B 0x8000 to addr 0x9000, little endian so it's 0x0080 in memory
 			pos := uint16(0x9000)
 			expected := uint16(0x8000)
-			cpu.memory[pos] = 0x00
-			cpu.memory[pos+1] = 0x80
+			cpu.ram()[pos] = 0x00
+			cpu.ram()[pos+1] = 0x80
 
 			dat := cpu.read16(pos)
 			_, _ = fmt.Printf("dat is %04x", dat)
@@ -204,3 +222,1013 @@ func TestMemory(t *testing.T) {
 		})
 	})
 }
+
+func TestArithmetic(t *testing.T) {
+	type tc struct {
+		name       string
+		a, m       byte
+		carry      bool
+		want       byte
+		c, v, z, n bool
+	}
+
+	Convey("should add and subtract with carry", t, func() {
+		cpu := newCPU()
+
+		Convey("adc immediate", func() {
+			cases := []tc{
+				{name: "1+1", a: 0x01, m: 0x01, want: 0x02},
+				{name: "1+1+c", a: 0x01, m: 0x01, carry: true, want: 0x03},
+				{name: "0+0 is zero", a: 0x00, m: 0x00, want: 0x00, z: true},
+				{name: "unsigned carry out", a: 0xff, m: 0x01, want: 0x00, c: true, z: true},
+				{name: "carry in causes carry out", a: 0xff, m: 0x00, carry: true, want: 0x00, c: true, z: true},
+				{name: "positive overflow 127+1", a: 0x7f, m: 0x01, want: 0x80, v: true, n: true},
+				{name: "positive overflow 80+80", a: 0x50, m: 0x50, want: 0xa0, v: true, n: true},
+				{name: "positive overflow via carry", a: 0x7f, m: 0x00, carry: true, want: 0x80, v: true, n: true},
+				{name: "negative overflow -128+-1", a: 0x80, m: 0xff, want: 0x7f, c: true, v: true},
+				{name: "negative overflow -128+-128", a: 0x80, m: 0x80, want: 0x00, c: true, v: true, z: true},
+				{name: "-1+-1 carries without overflow", a: 0xff, m: 0xff, want: 0xfe, c: true, n: true},
+				{name: "mixed signs never overflow", a: 0x7f, m: 0x80, want: 0xff, n: true},
+				{name: "mixed signs with carry", a: 0x7f, m: 0x80, carry: true, want: 0x00, c: true, z: true},
+				{name: "-1+1", a: 0xff, m: 0x01, want: 0x00, c: true, z: true},
+			}
+			for _, tt := range cases {
+				Convey(tt.name, func() {
+					cpu.a = tt.a
+					cpu.status.SetFlag(posC, tt.carry)
+					cpu.write(0x0010, tt.m)
+					cpu.adc(opDat{addr: 0x0010, mode: immediate})
+
+					So(cpu.a, ShouldEqual, tt.want)
+					So(cpu.status.Flag(posC), ShouldEqual, tt.c)
+					So(cpu.status.Flag(posV), ShouldEqual, tt.v)
+					So(cpu.status.Flag(posZ), ShouldEqual, tt.z)
+					So(cpu.status.Flag(posN), ShouldEqual, tt.n)
+				})
+			}
+		})
+
+		Convey("sbc immediate", func() {
+			// carry set means no borrow
+			cases := []tc{
+				{name: "2-1", a: 0x02, m: 0x01, carry: true, want: 0x01, c: true},
+				{name: "2-1-borrow", a: 0x02, m: 0x01, want: 0x00, c: true, z: true},
+				{name: "0-1 borrows", a: 0x00, m: 0x01, carry: true, want: 0xff, n: true},
+				{name: "0-0-borrow borrows", a: 0x00, m: 0x00, want: 0xff, n: true},
+				{name: "equal is zero", a: 0x42, m: 0x42, carry: true, want: 0x00, c: true, z: true},
+				{name: "negative overflow -128-1", a: 0x80, m: 0x01, carry: true, want: 0x7f, c: true, v: true},
+				{name: "positive overflow 127--1", a: 0x7f, m: 0xff, carry: true, want: 0x80, v: true, n: true},
+				{name: "positive overflow 0--128", a: 0x00, m: 0x80, carry: true, want: 0x80, v: true, n: true},
+				{name: "negative overflow via borrow", a: 0x80, m: 0x00, want: 0x7f, c: true, v: true},
+				{name: "same signs never overflow", a: 0xff, m: 0x80, carry: true, want: 0x7f, c: true},
+				{name: "-1-1", a: 0xff, m: 0x01, carry: true, want: 0xfe, c: true, n: true},
+			}
+			for _, tt := range cases {
+				Convey(tt.name, func() {
+					cpu.a = tt.a
+					cpu.status.SetFlag(posC, tt.carry)
+					cpu.write(0x0010, tt.m)
+					cpu.sbc(opDat{addr: 0x0010, mode: immediate})
+
+					So(cpu.a, ShouldEqual, tt.want)
+					So(cpu.status.Flag(posC), ShouldEqual, tt.c)
+					So(cpu.status.Flag(posV), ShouldEqual, tt.v)
+					So(cpu.status.Flag(posZ), ShouldEqual, tt.z)
+					So(cpu.status.Flag(posN), ShouldEqual, tt.n)
+				})
+			}
+		})
+
+		Convey("adc agrees with signed and unsigned math for every input", func() {
+			for a := 0; a < 256; a++ {
+				for m := 0; m < 256; m++ {
+					for _, carry := range []bool{false, true} {
+						cpu.a = byte(a)
+						cpu.status.SetFlag(posC, carry)
+						cpu.write(0x0010, byte(m))
+						cpu.adc(opDat{addr: 0x0010})
+
+						c := 0
+						if carry {
+							c = 1
+						}
+						signed := int(int8(a)) + int(int8(m)) + c
+						if cpu.status.Flag(posC) != (a+m+c > 0xff) || cpu.status.Flag(posV) != (signed < -128 || signed > 127) {
+							So(fmt.Sprintf("a=%02x m=%02x c=%v", a, m, carry), ShouldBeEmpty)
+						}
+					}
+				}
+			}
+		})
+
+		Convey("usbc behaves like sbc", func() {
+			cpu.a = 0x00
+			cpu.status.SetFlag(posC, true)
+			cpu.write(0x0010, 0x01)
+			cpu.usbc(opDat{addr: 0x0010})
+			So(cpu.a, ShouldEqual, 0xff)
+			So(cpu.status.Flag(posC), ShouldBeFalse)
+			So(cpu.status.Flag(posN), ShouldBeTrue)
+		})
+
+		Convey("programs", func() {
+			cpu.write16(0xFFFE, 0x1234)
+
+			Convey("adc zero page", func() {
+				cpu.write(0x0020, 0x05)
+				runProgram(cpu, []byte{0xa9, 0x03, 0x18, 0x65, 0x20, 0x00}) // LDA #3, CLC, ADC $20, BRK
+				So(cpu.a, ShouldEqual, 0x08)
+			})
+
+			Convey("adc absolute,x", func() {
+				cpu.write(0x0305, 0x40)
+				cpu.x = 0x05
+				runProgram(cpu, []byte{0xa9, 0x40, 0x18, 0x7d, 0x00, 0x03, 0x00}) // LDA #$40, CLC, ADC $0300,X, BRK
+				So(cpu.a, ShouldEqual, 0x80)
+				So(cpu.status.Flag(posV), ShouldBeTrue)
+			})
+
+			Convey("sbc (indirect),y", func() {
+				cpu.write16(0x0020, 0x0300)
+				cpu.write(0x0302, 0x01)
+				cpu.y = 0x02
+				cpu.status.SetFlag(posC, true)
+				runProgram(cpu, []byte{0xa9, 0x00, 0xf1, 0x20, 0x00}) // LDA #0, SBC ($20),Y, BRK
+				So(cpu.a, ShouldEqual, 0xff)
+				So(cpu.status.Flag(posC), ShouldBeFalse)
+			})
+
+			Convey("16 bit addition chains the carry", func() {
+				// $01ff + $0001 = $0200 with the result stored in $30/$31
+				runProgram(cpu, []byte{
+					0x18,       // CLC
+					0xa9, 0xff, // LDA #$ff
+					0x69, 0x01, // ADC #$01
+					0xaa,       // TAX (low byte)
+					0xa9, 0x01, // LDA #$01
+					0x69, 0x00, // ADC #$00
+					0x00,
+				})
+				So(cpu.x, ShouldEqual, 0x00)
+				So(cpu.a, ShouldEqual, 0x02)
+			})
+		})
+	})
+}
+
+func TestBranches(t *testing.T) {
+	Convey("should branch on flags", t, func() {
+		cpu := newCPU()
+
+		Convey("every branch is taken only when its flag matches", func() {
+			cases := []struct {
+				op    byte
+				taken func(s *Status) bool
+			}{
+				{0x10, func(s *Status) bool { return !s.Flag(posN) }}, // BPL
+				{0x30, func(s *Status) bool { return s.Flag(posN) }},  // BMI
+				{0x50, func(s *Status) bool { return !s.Flag(posV) }}, // BVC
+				{0x70, func(s *Status) bool { return s.Flag(posV) }},  // BVS
+				{0x90, func(s *Status) bool { return !s.Flag(posC) }}, // BCC
+				{0xB0, func(s *Status) bool { return s.Flag(posC) }},  // BCS
+				{0xD0, func(s *Status) bool { return !s.Flag(posZ) }}, // BNE
+				{0xF0, func(s *Status) bool { return s.Flag(posZ) }},  // BEQ
+			}
+			for _, tt := range cases {
+				for _, status := range []byte{0x00, posN, posV, posC, posZ, 0xff} {
+					cpu.status.Set(status)
+					cpu.pc = 0x0200
+					cpu.extraCycles = 0
+					cpu.opcodes[tt.op].Do(opDat{addr: 0x0210, pc: 0x0200, mode: relative})
+
+					if tt.taken(&cpu.status) {
+						So(cpu.pc, ShouldEqual, 0x0210)
+						So(cpu.extraCycles, ShouldEqual, 1)
+					} else {
+						So(cpu.pc, ShouldEqual, 0x0200)
+						So(cpu.extraCycles, ShouldEqual, 0)
+					}
+				}
+			}
+		})
+
+		Convey("taken branch to another page costs two extra cycles", func() {
+			cpu.status.SetFlag(posZ, true)
+			cpu.pc = 0x02FE
+			cpu.beq(opDat{addr: 0x0302, pc: 0x02FE, mode: relative})
+			So(cpu.pc, ShouldEqual, 0x0302)
+			So(cpu.extraCycles, ShouldEqual, 2)
+
+			cpu.extraCycles = 0
+			cpu.pc = 0x0302
+			cpu.beq(opDat{addr: 0x02F0, pc: 0x0302, mode: relative})
+			So(cpu.pc, ShouldEqual, 0x02F0)
+			So(cpu.extraCycles, ShouldEqual, 2)
+		})
+
+		Convey("page is compared against the next instruction not the branch itself", func() {
+			// branch at $02FD is 2 bytes so the next instruction is at $02FF, target $02FF+0 stays on the page
+			cpu.status.SetFlag(posC, true)
+			cpu.bcs(opDat{addr: 0x02FF, pc: 0x02FF, mode: relative})
+			So(cpu.extraCycles, ShouldEqual, 1)
+		})
+
+		Convey("loop terminates", func() {
+			cpu.write16(0xFFFE, 0x1234)
+			cpu.x = 0xfb
+			runProgram(cpu, []byte{
+				0xe8,       // loop: INX
+				0xd0, 0xfd, // BNE loop
+				0x00,
+			})
+			So(cpu.x, ShouldEqual, 0x00)
+			So(cpu.status.Flag(posZ), ShouldBeTrue)
+		})
+
+		Convey("forward branch skips code", func() {
+			cpu.write16(0xFFFE, 0x1234)
+			runProgram(cpu, []byte{
+				0xa9, 0x00, // LDA #0
+				0xf0, 0x01, // BEQ +1
+				0xe8, //       INX (skipped)
+				0x00,
+			})
+			So(cpu.x, ShouldEqual, 0x00)
+		})
+	})
+}
+
+func TestJumps(t *testing.T) {
+	Convey("should jump and return", t, func() {
+		cpu := newCPU()
+		cpu.write16(0xFFFE, 0x1234)
+		cpu.s = 0xff
+
+		Convey("jsr pushes the return address minus one", func() {
+			cpu.jsr(opDat{addr: 0x0400, pc: 0x0203, mode: absolute})
+			So(cpu.pc, ShouldEqual, 0x0400)
+			So(cpu.s, ShouldEqual, 0xfd)
+			So(cpu.read(0x01ff), ShouldEqual, 0x02) // hi
+			So(cpu.read(0x01fe), ShouldEqual, 0x02) // lo
+			So(cpu.pop16(), ShouldEqual, 0x0202)
+		})
+
+		Convey("rts returns to the address on the stack plus one", func() {
+			cpu.push16(0x0202)
+			cpu.rts(opDat{})
+			So(cpu.pc, ShouldEqual, 0x0203)
+			So(cpu.s, ShouldEqual, 0xff)
+		})
+
+		Convey("rti restores status and pc", func() {
+			cpu.push16(0x0345)
+			cpu.push(posN | posV | posZ | posC | posB | pos_)
+			cpu.rti(opDat{})
+			So(cpu.pc, ShouldEqual, 0x0345)
+			So(cpu.s, ShouldEqual, 0xff)
+			So(cpu.status.Get(), ShouldEqual, posN|posV|posZ|posC)
+		})
+
+		Convey("rti ignores B and bit 5 from the stack", func() {
+			cpu.status.Set(posB | pos_)
+			cpu.push16(0x0345)
+			cpu.push(posI)
+			cpu.rti(opDat{})
+			So(cpu.status.Get(), ShouldEqual, posB|pos_|posI)
+		})
+
+		Convey("jsr and rts program", func() {
+			runProgram(cpu, []byte{
+				0x20, 0x06, 0x00, // JSR sub
+				0xe8,       //       INX
+				0x00, 0x00, //       BRK
+				0xe8, //             sub: INX
+				0xe8, //             INX
+				0x60, //             RTS
+			})
+			So(cpu.x, ShouldEqual, 3)
+			So(cpu.s, ShouldEqual, 0xff-3) // only the BRK is left on the stack
+
+			_, oldPC := cpu.pop(), cpu.pop16()
+			So(oldPC, ShouldEqual, 6)
+		})
+
+		Convey("nested subroutines", func() {
+			runProgram(cpu, []byte{
+				0x20, 0x05, 0x00, // JSR a
+				0x00, 0x00, //       BRK
+				0xe8,             // a: INX
+				0x20, 0x0a, 0x00, // JSR b
+				0x60, //             RTS
+				0xe8, //             b: INX
+				0x60, //             RTS
+			})
+			So(cpu.x, ShouldEqual, 2)
+			_, oldPC := cpu.pop(), cpu.pop16()
+			So(oldPC, ShouldEqual, 5)
+		})
+
+		Convey("jmp absolute", func() {
+			runProgram(cpu, []byte{
+				0x4c, 0x05, 0x00, // JMP $0005
+				0xe8,       //             INX (skipped)
+				0xe8,       //             INX (skipped)
+				0x00, 0x00, //       BRK
+			})
+			So(cpu.x, ShouldEqual, 0)
+			_, oldPC := cpu.pop(), cpu.pop16()
+			So(oldPC, ShouldEqual, 7)
+		})
+
+		Convey("jmp indirect", func() {
+			cpu.write16(0x0120, 0x0300)
+			runProgram(cpu, []byte{0x6c, 0x20, 0x01}) // JMP ($0120) to a BRK at $0300
+			_, oldPC := cpu.pop(), cpu.pop16()
+			So(oldPC, ShouldEqual, 0x0302)
+		})
+
+		Convey("jmp indirect wraps the pointer within its page", func() {
+			cpu.write(0x02ff, 0x80)
+			cpu.write(0x0200, 0x03)                   // the buggy high byte
+			cpu.write(0x0300, 0x05)                   // the high byte you'd expect
+			cpu.write(0x0580, 0xe8)                   // INX that shouldn't run
+			runProgram(cpu, []byte{0x6c, 0xff, 0x02}) // JMP ($02FF) to a BRK at $0380 not $0580
+			So(cpu.x, ShouldEqual, 0)
+			_, oldPC := cpu.pop(), cpu.pop16()
+			So(oldPC, ShouldEqual, 0x0382)
+		})
+	})
+}
+
+func TestReadModifyWrite(t *testing.T) {
+	Convey("should shift, rotate, increment and decrement", t, func() {
+		cpu := newCPU()
+
+		cases := []struct {
+			name    string
+			fn      func(opDat)
+			in      byte
+			carry   bool
+			want    byte
+			c, z, n bool
+		}{
+			{name: "asl", fn: cpu.asl, in: 0x41, want: 0x82, n: true},
+			{name: "asl carry out", fn: cpu.asl, in: 0x80, want: 0x00, c: true, z: true},
+			{name: "asl ignores carry in", fn: cpu.asl, in: 0x01, carry: true, want: 0x02},
+			{name: "lsr", fn: cpu.lsr, in: 0x82, want: 0x41},
+			{name: "lsr carry out", fn: cpu.lsr, in: 0x01, want: 0x00, c: true, z: true},
+			{name: "lsr ignores carry in", fn: cpu.lsr, in: 0x80, carry: true, want: 0x40},
+			{name: "rol", fn: cpu.rol, in: 0x41, want: 0x82, n: true},
+			{name: "rol carry in", fn: cpu.rol, in: 0x40, carry: true, want: 0x81, n: true},
+			{name: "rol carry out", fn: cpu.rol, in: 0x80, want: 0x00, c: true, z: true},
+			{name: "rol carry through", fn: cpu.rol, in: 0xff, carry: true, want: 0xff, c: true, n: true},
+			{name: "ror", fn: cpu.ror, in: 0x82, want: 0x41},
+			{name: "ror carry in", fn: cpu.ror, in: 0x02, carry: true, want: 0x81, n: true},
+			{name: "ror carry out", fn: cpu.ror, in: 0x01, want: 0x00, c: true, z: true},
+			{name: "ror carry through", fn: cpu.ror, in: 0xff, carry: true, want: 0xff, c: true, n: true},
+		}
+
+		Convey("in accumulator mode", func() {
+			for _, tt := range cases {
+				cpu.a = tt.in
+				cpu.status.SetFlag(posC, tt.carry)
+				cpu.write(0x0010, 0x55)
+				tt.fn(opDat{addr: 0x0010, mode: accumulator})
+
+				So(cpu.a, ShouldEqual, tt.want)
+				So(cpu.read(0x0010), ShouldEqual, 0x55) // memory untouched
+				So(cpu.status.Flag(posC), ShouldEqual, tt.c)
+				So(cpu.status.Flag(posZ), ShouldEqual, tt.z)
+				So(cpu.status.Flag(posN), ShouldEqual, tt.n)
+			}
+		})
+
+		Convey("on memory", func() {
+			for _, tt := range cases {
+				cpu.a = 0x55
+				cpu.status.SetFlag(posC, tt.carry)
+				cpu.write(0x0010, tt.in)
+				tt.fn(opDat{addr: 0x0010, mode: zeroPage})
+
+				So(cpu.read(0x0010), ShouldEqual, tt.want)
+				So(cpu.a, ShouldEqual, 0x55) // accumulator untouched
+				So(cpu.status.Flag(posC), ShouldEqual, tt.c)
+				So(cpu.status.Flag(posZ), ShouldEqual, tt.z)
+				So(cpu.status.Flag(posN), ShouldEqual, tt.n)
+			}
+		})
+
+		Convey("inc and dec wrap and leave carry alone", func() {
+			cpu.status.SetFlag(posC, true)
+			cpu.write(0x0300, 0xff)
+			cpu.inc(opDat{addr: 0x0300, mode: absolute})
+			So(cpu.read(0x0300), ShouldEqual, 0x00)
+			So(cpu.status.Flag(posZ), ShouldBeTrue)
+			So(cpu.status.Flag(posC), ShouldBeTrue)
+
+			cpu.dec(opDat{addr: 0x0300, mode: absolute})
+			So(cpu.read(0x0300), ShouldEqual, 0xff)
+			So(cpu.status.Flag(posN), ShouldBeTrue)
+			So(cpu.status.Flag(posZ), ShouldBeFalse)
+			So(cpu.status.Flag(posC), ShouldBeTrue)
+
+			cpu.write(0x0300, 0x7f)
+			cpu.inc(opDat{addr: 0x0300, mode: absolute})
+			So(cpu.read(0x0300), ShouldEqual, 0x80)
+			So(cpu.status.Flag(posN), ShouldBeTrue)
+		})
+
+		Convey("programs", func() {
+			cpu.write16(0xFFFE, 0x1234)
+
+			Convey("asl a and rol a make a 16 bit shift", func() {
+				runProgram(cpu, []byte{
+					0xa9, 0xc0, // LDA #$c0
+					0x0a,       //       ASL A
+					0xaa,       //       TAX (discard, flags from A are recomputed below)
+					0xa9, 0x01, // LDA #$01
+					0x2a, //       ROL A
+					0x00,
+				})
+				So(cpu.x, ShouldEqual, 0x80)
+				So(cpu.a, ShouldEqual, 0x03)
+				So(cpu.status.Flag(posC), ShouldBeFalse)
+			})
+
+			Convey("memory modes", func() {
+				cpu.write(0x0020, 0x01)
+				cpu.write(0x0025, 0x80)
+				cpu.write(0x0305, 0x10)
+				cpu.x = 0x05
+				runProgram(cpu, []byte{
+					0xe6, 0x20, //       INC $20
+					0x56, 0x20, //       LSR $20,X
+					0x1e, 0x00, 0x03, // ASL $0300,X
+					0xce, 0x05, 0x03, // DEC $0305
+					0x00,
+				})
+				So(cpu.read(0x0020), ShouldEqual, 0x02)
+				So(cpu.read(0x0025), ShouldEqual, 0x40)
+				So(cpu.read(0x0305), ShouldEqual, 0x1f)
+			})
+		})
+	})
+}
+
+func TestInstructions(t *testing.T) {
+	Convey("should test loads, stores, transfers, stack ops and compares", t, func() {
+		cpu := newCPU()
+		cpu.write16(0xFFFE, 0x1234) // put 1234 at the IRQ interrupt vector
+
+		Convey("ldx and ldy", func() {
+			runProgram(cpu, []byte{0xa2, 0x42, 0xa0, 0x00, 0x00}) // LDX #$42, LDY #0, BRK
+			So(cpu.x, ShouldEqual, 0x42)
+			So(cpu.y, ShouldEqual, 0x00)
+			So(cpu.status.Flag(posZ), ShouldBeTrue)
+		})
+
+		Convey("ldx and ldy from memory", func() {
+			cpu.write(0x0040, 0x05)
+			cpu.write(0x0345, 0x80)
+			runProgram(cpu, []byte{0xa6, 0x40, 0xbc, 0x40, 0x03, 0x00}) // LDX $40, LDY $0340,X, BRK
+			So(cpu.x, ShouldEqual, 0x05)
+			So(cpu.y, ShouldEqual, 0x80)
+			So(cpu.status.Flag(posN), ShouldBeTrue)
+		})
+
+		Convey("ldx zero page y", func() {
+			cpu.write(0x0045, 0xff)
+			cpu.y = 0x05
+			runProgram(cpu, []byte{0xb6, 0x40, 0x00}) // LDX $40,Y
+			So(cpu.x, ShouldEqual, 0xff)
+			So(cpu.status.Flag(posN), ShouldBeTrue)
+		})
+
+		Convey("sta, stx and sty", func() {
+			cpu.a, cpu.x, cpu.y = 0x11, 0x22, 0x33
+			runProgram(cpu, []byte{
+				0x85, 0x40, // STA $40
+				0x86, 0x41, // STX $41
+				0x84, 0x42, // STY $42
+				0x8d, 0x00, 0x03, // STA $0300
+				0x99, 0x00, 0x03, // STA $0300,Y
+				0x96, 0x10, // STX $10,Y
+				0x94, 0x10, // STY $10,X
+				0x00,
+			})
+			So(cpu.read(0x0040), ShouldEqual, 0x11)
+			So(cpu.read(0x0041), ShouldEqual, 0x22)
+			So(cpu.read(0x0042), ShouldEqual, 0x33)
+			So(cpu.read(0x0300), ShouldEqual, 0x11)
+			So(cpu.read(0x0333), ShouldEqual, 0x11)
+			So(cpu.read(0x0043), ShouldEqual, 0x22)
+			So(cpu.read(0x0032), ShouldEqual, 0x33)
+		})
+
+		Convey("stores don't touch flags", func() {
+			cpu.a = 0x00
+			cpu.status.Set(posN)
+			cpu.sta(opDat{addr: 0x0040})
+			So(cpu.status.Get(), ShouldEqual, posN)
+		})
+
+		Convey("register transfers", func() {
+			runProgram(cpu, []byte{
+				0xa9, 0x80, // LDA #$80
+				0xa8,       // TAY
+				0xa2, 0x00, // LDX #$00
+				0x8a, // TXA
+				0x00,
+			})
+			So(cpu.y, ShouldEqual, 0x80)
+			So(cpu.a, ShouldEqual, 0x00)
+			So(cpu.status.Flag(posZ), ShouldBeTrue)
+			So(cpu.status.Flag(posN), ShouldBeFalse)
+
+			cpu.y = 0xfe
+			cpu.tya(opDat{})
+			So(cpu.a, ShouldEqual, 0xfe)
+			So(cpu.status.Flag(posN), ShouldBeTrue)
+		})
+
+		Convey("stack pointer transfers", func() {
+			cpu.x = 0x00
+			cpu.s = 0x80
+			cpu.status.Set(posZ)
+			cpu.txs(opDat{})
+			So(cpu.s, ShouldEqual, 0x00)
+			So(cpu.status.Get(), ShouldEqual, posZ) // txs sets no flags
+
+			cpu.s = 0xf0
+			cpu.tsx(opDat{})
+			So(cpu.x, ShouldEqual, 0xf0)
+			So(cpu.status.Flag(posN), ShouldBeTrue)
+			So(cpu.status.Flag(posZ), ShouldBeFalse)
+		})
+
+		Convey("increments and decrements", func() {
+			cpu.x, cpu.y = 0x01, 0x00
+			runProgram(cpu, []byte{0xca, 0x88, 0x00}) // DEX, DEY
+			So(cpu.x, ShouldEqual, 0x00)
+			So(cpu.y, ShouldEqual, 0xff)
+			So(cpu.status.Flag(posN), ShouldBeTrue)
+			So(cpu.status.Flag(posZ), ShouldBeFalse)
+
+			cpu.iny(opDat{})
+			So(cpu.y, ShouldEqual, 0x00)
+			So(cpu.status.Flag(posZ), ShouldBeTrue)
+		})
+
+		Convey("loop with dex", func() {
+			runProgram(cpu, []byte{
+				0xa2, 0x05, // LDX #5
+				0xa0, 0x00, // LDY #0
+				0xc8,       // loop: INY
+				0xca,       // DEX
+				0xd0, 0xfc, // BNE loop
+				0x00,
+			})
+			So(cpu.x, ShouldEqual, 0)
+			So(cpu.y, ShouldEqual, 5)
+		})
+
+		Convey("pha and pla", func() {
+			cpu.s = 0xff
+			runProgram(cpu, []byte{
+				0xa9, 0x42, // LDA #$42
+				0x48,       // PHA
+				0xa9, 0x00, // LDA #0
+				0x68, // PLA
+				0x00,
+			})
+			So(cpu.a, ShouldEqual, 0x42)
+			So(cpu.status.Flag(posZ), ShouldBeFalse)
+			So(cpu.s, ShouldEqual, 0xff-3) // just the BRK left on the stack
+		})
+
+		Convey("pla sets flags", func() {
+			cpu.s = 0xff
+			cpu.push(0x00)
+			cpu.pla(opDat{})
+			So(cpu.status.Flag(posZ), ShouldBeTrue)
+			cpu.push(0x80)
+			cpu.pla(opDat{})
+			So(cpu.status.Flag(posN), ShouldBeTrue)
+		})
+
+		Convey("php and plp", func() {
+			cpu.s = 0xff
+			cpu.status.Set(posC | posZ)
+			cpu.php(opDat{})
+			cpu.status.Clear()
+
+			cpu.plp(opDat{})
+			So(cpu.status.Get(), ShouldEqual, posC|posZ)
+			So(cpu.s, ShouldEqual, 0xff)
+
+			// B and bit 5 can't be pulled
+			cpu.push(0xff)
+			cpu.plp(opDat{})
+			So(cpu.status.Get(), ShouldEqual, 0xff&^(posB|pos_))
+		})
+
+		Convey("compares", func() {
+			cases := []struct {
+				reg, m  byte
+				c, z, n bool
+			}{
+				{reg: 0x10, m: 0x10, c: true, z: true},
+				{reg: 0x10, m: 0x0f, c: true},
+				{reg: 0x10, m: 0x11, n: true},
+				{reg: 0x00, m: 0xff},                   // 0-255 = 1
+				{reg: 0xff, m: 0x00, c: true, n: true}, // unsigned compare
+				{reg: 0x80, m: 0x01, c: true},
+			}
+			for _, tt := range cases {
+				cpu.write(0x0040, tt.m)
+				for _, cmp := range []struct {
+					fn  func(opDat)
+					reg *byte
+				}{{cpu.cmp, &cpu.a}, {cpu.cpx, &cpu.x}, {cpu.cpy, &cpu.y}} {
+					cpu.a, cpu.x, cpu.y = 0, 0, 0
+					*cmp.reg = tt.reg
+					cmp.fn(opDat{addr: 0x0040})
+
+					So(*cmp.reg, ShouldEqual, tt.reg)
+					So(cpu.status.Flag(posC), ShouldEqual, tt.c)
+					So(cpu.status.Flag(posZ), ShouldEqual, tt.z)
+					So(cpu.status.Flag(posN), ShouldEqual, tt.n)
+				}
+			}
+		})
+
+		Convey("compare and branch program", func() {
+			runProgram(cpu, []byte{
+				0xa2, 0x00, // LDX #0
+				0xe8,       // loop: INX
+				0xe0, 0x0a, // CPX #10
+				0xd0, 0xfb, // BNE loop
+				0x00,
+			})
+			So(cpu.x, ShouldEqual, 10)
+			So(cpu.status.Flag(posC), ShouldBeTrue)
+		})
+
+		Convey("bit", func() {
+			cpu.write(0x0040, 0xc0)
+			cpu.a = 0x01
+			cpu.bit(opDat{addr: 0x0040})
+			So(cpu.status.Flag(posZ), ShouldBeTrue)
+			So(cpu.status.Flag(posN), ShouldBeTrue)
+			So(cpu.status.Flag(posV), ShouldBeTrue)
+			So(cpu.a, ShouldEqual, 0x01)
+
+			cpu.write(0x0040, 0x01)
+			cpu.bit(opDat{addr: 0x0040})
+			So(cpu.status.Flag(posZ), ShouldBeFalse)
+			So(cpu.status.Flag(posN), ShouldBeFalse)
+			So(cpu.status.Flag(posV), ShouldBeFalse)
+		})
+
+		Convey("ora and eor", func() {
+			cpu.write(0x0040, 0x0f)
+			runProgram(cpu, []byte{
+				0xa9, 0xf0, // LDA #$f0
+				0x05, 0x40, // ORA $40
+				0x49, 0xff, // EOR #$ff
+				0x00,
+			})
+			So(cpu.a, ShouldEqual, 0x00)
+			So(cpu.status.Flag(posZ), ShouldBeTrue)
+		})
+
+		Convey("sec and sed", func() {
+			runProgram(cpu, []byte{0x38, 0xf8, 0x00}) // SEC, SED
+			So(cpu.status.Flag(posC), ShouldBeTrue)
+			So(cpu.status.Flag(posD), ShouldBeTrue)
+		})
+	})
+}
+
+func TestIllegalOpcodes(t *testing.T) {
+	Convey("should run the undocumented opcodes", t, func() {
+		cpu := newCPU()
+		cpu.write16(0xFFFE, 0x1234)
+
+		Convey("combined read-modify-write ops", func() {
+			cases := []struct {
+				name       string
+				fn         func(opDat)
+				a, m       byte
+				carry      bool
+				wantA      byte
+				wantM      byte
+				c, z, n, v bool
+			}{
+				{name: "slo", fn: cpu.slo, a: 0x01, m: 0x81, wantA: 0x03, wantM: 0x02, c: true},
+				{name: "rla", fn: cpu.rla, a: 0xff, m: 0x40, carry: true, wantA: 0x81, wantM: 0x81, n: true},
+				{name: "sre", fn: cpu.sre, a: 0xff, m: 0x03, wantA: 0xfe, wantM: 0x01, c: true, n: true},
+				{name: "rra", fn: cpu.rra, a: 0x01, m: 0x03, wantA: 0x03, wantM: 0x01}, // ror gives 1 with carry out, 1+1+1
+				{name: "rra overflow", fn: cpu.rra, a: 0x7f, m: 0x02, carry: true, wantA: 0x00, wantM: 0x81, c: true, z: true},
+				{name: "dcp equal", fn: cpu.dcp, a: 0x41, m: 0x42, wantA: 0x41, wantM: 0x41, c: true, z: true},
+				{name: "dcp less", fn: cpu.dcp, a: 0x00, m: 0x00, wantA: 0x00, wantM: 0xff},
+				{name: "isc", fn: cpu.isc, a: 0x05, m: 0x02, carry: true, wantA: 0x02, wantM: 0x03, c: true},
+				{name: "isc borrow", fn: cpu.isc, a: 0x00, m: 0xff, carry: true, wantA: 0x00, wantM: 0x00, c: true, z: true},
+			}
+			for _, tt := range cases {
+				cpu.status.Clear()
+				cpu.a = tt.a
+				cpu.status.SetFlag(posC, tt.carry)
+				cpu.write(0x0040, tt.m)
+				tt.fn(opDat{addr: 0x0040, mode: zeroPage})
+
+				So(cpu.a, ShouldEqual, tt.wantA)
+				So(cpu.read(0x0040), ShouldEqual, tt.wantM)
+				So(cpu.status.Flag(posC), ShouldEqual, tt.c)
+				So(cpu.status.Flag(posZ), ShouldEqual, tt.z)
+				So(cpu.status.Flag(posN), ShouldEqual, tt.n)
+				So(cpu.status.Flag(posV), ShouldEqual, tt.v)
+			}
+		})
+
+		Convey("immediate ops", func() {
+			cpu.write(0x0040, 0xf0)
+
+			cpu.a = 0x81
+			cpu.anc(opDat{addr: 0x0040})
+			So(cpu.a, ShouldEqual, 0x80)
+			So(cpu.status.Flag(posC), ShouldBeTrue)
+			So(cpu.status.Flag(posN), ShouldBeTrue)
+
+			cpu.a = 0x31
+			cpu.alr(opDat{addr: 0x0040})
+			So(cpu.a, ShouldEqual, 0x18)
+			So(cpu.status.Flag(posC), ShouldBeFalse)
+
+			cpu.a = 0xff
+
+			cpu.status.SetFlag(posC, true)
+			cpu.arr(opDat{addr: 0x0040}) // (ff&f0) ror = f8
+			So(cpu.a, ShouldEqual, 0xf8)
+			So(cpu.status.Flag(posC), ShouldBeTrue)  // bit 6
+			So(cpu.status.Flag(posV), ShouldBeFalse) // bit 6 ^ bit 5
+			So(cpu.status.Flag(posN), ShouldBeTrue)
+
+			cpu.a = 0x40
+
+			cpu.status.SetFlag(posC, false)
+			cpu.write(0x0041, 0xff)
+			cpu.arr(opDat{addr: 0x0041}) // 40 ror = 20
+			So(cpu.a, ShouldEqual, 0x20)
+			So(cpu.status.Flag(posC), ShouldBeFalse)
+			So(cpu.status.Flag(posV), ShouldBeTrue)
+
+			cpu.a, cpu.x = 0xf3, 0x3f
+			cpu.write(0x0042, 0x02)
+			cpu.sbx(opDat{addr: 0x0042}) // (f3&3f) - 2 = 31
+			So(cpu.x, ShouldEqual, 0x31)
+			So(cpu.a, ShouldEqual, 0xf3)
+			So(cpu.status.Flag(posC), ShouldBeTrue)
+
+			cpu.a, cpu.x = 0x01, 0xff
+			cpu.sbx(opDat{addr: 0x0042}) // 1 - 2 borrows
+			So(cpu.x, ShouldEqual, 0xff)
+			So(cpu.status.Flag(posC), ShouldBeFalse)
+			So(cpu.status.Flag(posN), ShouldBeTrue)
+		})
+
+		Convey("lax, sax and las", func() {
+			cpu.write(0x0040, 0x80)
+			cpu.lax(opDat{addr: 0x0040})
+			So(cpu.a, ShouldEqual, 0x80)
+			So(cpu.x, ShouldEqual, 0x80)
+			So(cpu.status.Flag(posN), ShouldBeTrue)
+
+			cpu.a, cpu.x = 0xf0, 0x3c
+			cpu.status.Clear()
+			cpu.sax(opDat{addr: 0x0041})
+			So(cpu.read(0x0041), ShouldEqual, 0x30)
+			So(cpu.status.Get(), ShouldEqual, 0x00)
+
+			cpu.s = 0xf3
+			cpu.write(0x0042, 0x3f)
+			cpu.las(opDat{addr: 0x0042})
+			So(cpu.a, ShouldEqual, 0x33)
+			So(cpu.x, ShouldEqual, 0x33)
+			So(cpu.s, ShouldEqual, 0x33)
+		})
+
+		Convey("unstable ops use the magic constant", func() {
+			cpu.write(0x0040, 0xff)
+
+			cpu.a, cpu.x = 0x00, 0x0f
+			cpu.ane(opDat{addr: 0x0040})
+			So(cpu.a, ShouldEqual, defaultMagic&0x0f)
+
+			cpu.magic = 0xff
+			cpu.a, cpu.x = 0x00, 0x0f
+			cpu.ane(opDat{addr: 0x0040})
+			So(cpu.a, ShouldEqual, 0x0f)
+
+			cpu.magic = 0x00
+			cpu.a = 0x11
+			cpu.lxa(opDat{addr: 0x0040})
+			So(cpu.a, ShouldEqual, 0x11)
+			So(cpu.x, ShouldEqual, 0x11)
+		})
+
+		Convey("sha, shx, shy and tas store AND the high byte plus one", func() {
+			cpu.a, cpu.x, cpu.y = 0xff, 0xff, 0x10
+			cpu.shx(opDat{addr: 0x0310, mode: absoluteY}) // base 0x0300
+			So(cpu.read(0x0310), ShouldEqual, 0x04)
+
+			cpu.x, cpu.y = 0x10, 0xff
+			cpu.shy(opDat{addr: 0x0320, mode: absoluteX}) // base 0x0310
+			So(cpu.read(0x0320), ShouldEqual, 0x04)
+
+			cpu.a, cpu.x, cpu.y = 0xff, 0xff, 0x10
+			cpu.sha(opDat{addr: 0x0710, mode: absoluteY})
+			So(cpu.read(0x0710), ShouldEqual, 0x08)
+
+			cpu.a, cpu.x = 0xf7, 0x7f
+			cpu.tas(opDat{addr: 0x0610, mode: absoluteY})
+			So(cpu.s, ShouldEqual, 0x77)
+			So(cpu.read(0x0610), ShouldEqual, 0x07)
+		})
+
+		Convey("sha on a page cross corrupts the target high byte", func() {
+			cpu.a, cpu.x, cpu.y = 0xff, 0x03, 0x20
+			cpu.sha(opDat{addr: 0x0510, mode: absoluteY}) // base 0x04f0, value 0x03&0x05 = 0x01
+			So(cpu.read(0x0510), ShouldEqual, 0x00)
+			So(cpu.read(0x0110), ShouldEqual, 0x01)
+		})
+
+		Convey("multi byte nops skip their operands", func() {
+			runProgram(cpu, []byte{
+				0x04, 0x40, // NOP $40
+				0x0c, 0x00, 0x03, // NOP $0300
+				0x1a,       // NOP
+				0x80, 0xe8, // NOP #$e8
+				0x00,
+			})
+			So(cpu.x, ShouldEqual, 0)
+			So(cpu.a, ShouldEqual, 0)
+			_, oldPC := cpu.pop(), cpu.pop16()
+			So(oldPC, ShouldEqual, 0x000a)
+		})
+
+		Convey("every illegal opcode is marked", func() {
+			count := 0
+			for _, op := range cpu.opcodes {
+				if op.Illegal {
+					count++
+				}
+			}
+			So(count, ShouldEqual, 105)
+			So(cpu.opcodes[0xe9].Illegal, ShouldBeFalse) // the real SBC
+			So(cpu.opcodes[0xeb].Illegal, ShouldBeTrue)  // the USBC copy
+			So(cpu.opcodes[0x9e].Illegal, ShouldBeTrue)  // SHX
+			So(cpu.opcodes[0xea].Illegal, ShouldBeFalse) // the real NOP
+		})
+
+		Convey("strict mode faults on illegal opcodes", func() {
+			cpu.strict = true
+			stop := runProgram(cpu, []byte{0xea, 0xa7, 0x40, 0x00})
+			So(stop, ShouldResemble, Stop{Reason: StopIllegal, PC: 1, Opcode: 0xa7, Err: &IllegalOpcodeError{PC: 1, Opcode: 0xa7, Name: "LAX"}})
+			So(cpu.pc, ShouldEqual, 1) // the illegal opcode didn't run
+		})
+
+		Convey("strict mode runs legal programs", func() {
+			cpu.strict = true
+			runProgram(cpu, []byte{0xa9, 0x01, 0xea, 0x00})
+			So(cpu.a, ShouldEqual, 0x01)
+		})
+	})
+}
+
+func TestJam(t *testing.T) {
+	Convey("should halt on JAM", t, func() {
+		cpu := newCPU()
+		cpu.write16(0xFFFE, 0x1234)
+
+		Convey("jam stops with the pc and opcode that jammed", func() {
+			stop := runProgram(cpu, []byte{0xe8, 0xe8, 0x02, 0xe8, 0x00}) // INX, INX, JAM, INX, BRK
+			So(stop, ShouldResemble, Stop{Reason: StopJam, PC: 0x0002, Opcode: 0x02})
+			So(cpu.halted, ShouldBeTrue)
+			So(cpu.x, ShouldEqual, 2) // nothing after the JAM ran
+			So(cpu.pc, ShouldEqual, 0x0002)
+			So(cpu.status.Flag(posB), ShouldBeFalse)
+		})
+
+		Convey("every JAM opcode halts", func() {
+			for _, jam := range []byte{0x02, 0x12, 0x22, 0x32, 0x42, 0x52, 0x62, 0x72, 0x92, 0xB2, 0xD2, 0xF2} {
+				cpu.reset()
+				cpu.pc = 0
+				stop := runProgram(cpu, []byte{jam, 0x00})
+				So(stop, ShouldResemble, Stop{Reason: StopJam, PC: 0x0000, Opcode: jam})
+			}
+		})
+
+		Convey("jam takes the same cycles in both executors", func() {
+			for _, accurate := range []bool{false, true} {
+				cpu := New(WithAccurate(accurate))
+				cpu.Poke(0x0000, 0x02) // JAM
+				info, err := cpu.Step()
+				So(err, ShouldBeNil)
+				So(info.Cycles, ShouldEqual, 2)
+				So(cpu.Cycles(), ShouldEqual, 7+2)
+			}
+		})
+
+		Convey("stays halted until reset", func() {
+			runProgram(cpu, []byte{0x12})
+
+			stop := runProgram(cpu, []byte{0xe8, 0x00}) // the JAM got overwritten but the cpu is still latched
+			So(stop.Reason, ShouldEqual, StopJam)
+			So(cpu.x, ShouldEqual, 0)
+
+			cpu.write16(pcInitAddr, 0x0000)
+			cpu.reset()
+			So(cpu.halted, ShouldBeFalse)
+			stop = runProgram(cpu, []byte{0xe8, 0x00})
+			So(stop.Reason, ShouldEqual, StopBreakpoint)
+			So(cpu.x, ShouldEqual, 1)
+		})
+
+	})
+}
+
+func TestCycles(t *testing.T) {
+	Convey("should count cycles", t, func() {
+		cpu := newCPU()
+		cpu.s = 0xff
+
+		run := func(program ...byte) int {
+			copy(cpu.ram()[0x0200:], program)
+			cpu.pc = 0x0200
+			return stepCycles(cpu.Step())
+		}
+
+		Convey("base cycles", func() {
+			So(run(0xa9, 0x01), ShouldEqual, 2)       // LDA #
+			So(run(0xa5, 0x40), ShouldEqual, 3)       // LDA zp
+			So(run(0xb5, 0x40), ShouldEqual, 4)       // LDA zp,X
+			So(run(0xad, 0x00, 0x03), ShouldEqual, 4) // LDA abs
+			So(run(0xa1, 0x40), ShouldEqual, 6)       // LDA (zp,X)
+			So(run(0x8d, 0x00, 0x03), ShouldEqual, 4) // STA abs
+			So(run(0xee, 0x00, 0x03), ShouldEqual, 6) // INC abs
+			So(run(0x48), ShouldEqual, 3)             // PHA
+			So(run(0x68), ShouldEqual, 4)             // PLA
+			So(run(0x20, 0x00, 0x03), ShouldEqual, 6) // JSR
+			So(run(0x60), ShouldEqual, 6)             // RTS
+			So(run(0x4c, 0x00, 0x03), ShouldEqual, 3) // JMP abs
+			So(run(0x6c, 0x00, 0x03), ShouldEqual, 5) // JMP (ind)
+		})
+
+		Convey("indexed reads take an extra cycle when crossing a page", func() {
+			cpu.x, cpu.y = 0x10, 0x10
+			So(run(0xbd, 0x00, 0x03), ShouldEqual, 4) // LDA $0300,X
+			So(run(0xbd, 0xf0, 0x03), ShouldEqual, 5) // LDA $03F0,X
+			So(run(0xb9, 0x00, 0x03), ShouldEqual, 4) // LDA $0300,Y
+			So(run(0xb9, 0xf8, 0x03), ShouldEqual, 5) // LDA $03F8,Y
+			So(run(0x1c, 0xf8, 0x03), ShouldEqual, 5) // NOP $03F8,X (illegal)
+
+			cpu.write16(0x0040, 0x0300)
+			So(run(0xb1, 0x40), ShouldEqual, 5) // LDA ($40),Y
+			cpu.write16(0x0040, 0x03ff)
+			So(run(0xb1, 0x40), ShouldEqual, 6) // LDA ($40),Y
+		})
+
+		Convey("stores and read-modify-writes don't", func() {
+			cpu.x, cpu.y = 0x10, 0x10
+			So(run(0x9d, 0x00, 0x03), ShouldEqual, 5) // STA $0300,X
+			So(run(0x9d, 0xf0, 0x03), ShouldEqual, 5) // STA $03F0,X
+			So(run(0xfe, 0x00, 0x03), ShouldEqual, 7) // INC $0300,X
+			So(run(0xfe, 0xf0, 0x03), ShouldEqual, 7) // INC $03F0,X
+
+			cpu.write16(0x0040, 0x03ff)
+			So(run(0x91, 0x40), ShouldEqual, 6) // STA ($40),Y
+		})
+
+		Convey("branches", func() {
+			cpu.status.SetFlag(posZ, false)
+			So(run(0xf0, 0x10), ShouldEqual, 2) // BEQ not taken
+			So(run(0xd0, 0x10), ShouldEqual, 3) // BNE taken
+			So(cpu.pc, ShouldEqual, 0x0212)
+			So(run(0xd0, 0xf0), ShouldEqual, 4) // BNE taken back to the previous page
+			So(cpu.pc, ShouldEqual, 0x01f2)
+		})
+
+		Convey("running count", func() {
+			cpu.cycles = 0
+			cpu.write16(0xFFFE, 0x1234)
+			runProgram(cpu, []byte{
+				0xa2, 0x03, // LDX #3 (2)
+				0xca,       // loop: DEX (2 each)
+				0xd0, 0xfd, // BNE loop (3 taken twice, 2 not taken)
+				0x00, // BRK (7)
+			})
+			So(cpu.cycles, ShouldEqual, 2+3*2+3+3+2+7)
+		})
+	})
+}
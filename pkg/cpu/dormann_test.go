@@ -0,0 +1,155 @@
+package cpu
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// Klaus Dormann's 6502 functional and interrupt tests go through every instruction, mode and flag, and trap,
+// jump to themselves, as soon as something's wrong or at the end when everything passed.
+// The binaries aren't in the repo, build them or take the ones in bin_files and put them in testdata to run them:
+// https://github.com/Klaus2m5/6502_65C02_functional_tests
+//
+// Both are 64KiB images loaded at $0000 that start at $0400. These are the addresses of the success traps of the
+// binaries in bin_files, they move if the tests are built with a different configuration.
+const (
+	dormannStart             = 0x0400
+	dormannFunctionalSuccess = 0x3469
+	dormannInterruptSuccess  = 0x06F5
+	// dormannTestCase is where the tests keep the number of the test they're running.
+	dormannTestCase = 0x0200
+)
+
+// The interrupt test drives the IRQ and NMI lines with a feedback register, setting a bit asserts its line.
+const (
+	feedbackPort = 0xBFFC
+	feedbackIRQ  = 1 << 0
+	feedbackNMI  = 1 << 1
+)
+
+// feedbackBus is flat RAM with the interrupt test's feedback register wired to the CPU's interrupt lines.
+type feedbackBus struct {
+	RAM
+	cpu *CPU
+}
+
+func (b *feedbackBus) Write(addr uint16, dat byte) {
+	b.RAM.Write(addr, dat)
+	if addr == feedbackPort {
+		b.cpu.SetIRQ(dat&feedbackIRQ != 0)
+		b.cpu.SetNMI(dat&feedbackNMI != 0)
+	}
+}
+
+// dormannTrap is where a test got stuck and the number of the test it was on.
+type dormannTrap struct {
+	pc   uint16
+	test byte
+}
+
+func (t dormannTrap) String() string {
+	return fmt.Sprintf("trapped at $%04X in test $%02X", t.pc, t.test)
+}
+
+// runDormann runs until the pc jumps to itself. A jump to itself while an interrupt is due isn't a trap since the
+// interrupt gets it out of there.
+func runDormann(cpu *CPU, budget uint64) (dormannTrap, error) {
+	for start := cpu.cycles; cpu.cycles-start < budget; {
+		pc := cpu.pc
+		info, err := cpu.Step()
+		if err != nil {
+			return dormannTrap{}, err
+		}
+		if cpu.halted {
+			return dormannTrap{}, fmt.Errorf("jammed at $%04X", pc)
+		}
+		due := cpu.nmiPending || cpu.irqLine && !cpu.status.Flag(posI)
+		if !info.Interrupt && cpu.pc == pc && !due {
+			return dormannTrap{pc: pc, test: cpu.peek(dormannTestCase)}, nil
+		}
+	}
+	return dormannTrap{}, fmt.Errorf("didn't trap in %v cycles, the pc is $%04X", budget, cpu.pc)
+}
+
+// newDormannCPU loads the image at $0000 on a bus with the feedback register and points the CPU at the start.
+func newDormannCPU(image []byte, opts ...Option) *CPU {
+	bus := &feedbackBus{}
+	copy(bus.RAM[:], image)
+	cpu := New(append([]Option{WithBus(bus), WithVariant(VariantNMOS)}, opts...)...)
+	bus.cpu = cpu
+	cpu.SetPC(dormannStart)
+	return cpu
+}
+
+func TestDormannRunner(t *testing.T) {
+	Convey("the runner", t, func() {
+		image := make([]byte, 0x10000)
+
+		Convey("reports the trap and the test it was on", func() {
+			copy(image[0x0400:], []byte{0xa9, 0x05, 0x8d, 0x00, 0x02, 0x4c, 0x05, 0x04}) // LDA #5, STA test case, JMP *
+			trap, err := runDormann(newDormannCPU(image), 1000)
+			So(err, ShouldBeNil)
+			So(trap.String(), ShouldEqual, "trapped at $0405 in test $05")
+		})
+
+		Convey("drives interrupts from the feedback register", func() {
+			copy(image[0x0400:], []byte{
+				0x58,       // CLI
+				0xa9, 0x01, // LDA #IRQ
+				0x8d, 0xfc, 0xbf, // STA feedback
+				0xa9, 0x02, // LDA #NMI
+				0x8d, 0xfc, 0xbf, // STA feedback
+				0x4c, 0x0b, 0x04, // JMP *
+			})
+			// the handlers release the line, count themselves and return
+			copy(image[0x0500:], []byte{0xa9, 0x00, 0x8d, 0xfc, 0xbf, 0xe6, 0x10, 0x40}) // IRQ: INC $10
+			copy(image[0x0600:], []byte{0xa9, 0x00, 0x8d, 0xfc, 0xbf, 0xe6, 0x11, 0x40}) // NMI: INC $11
+			image[irqVector], image[irqVector+1] = 0x00, 0x05
+			image[nmiVector], image[nmiVector+1] = 0x00, 0x06
+
+			cpu := newDormannCPU(image)
+			trap, err := runDormann(cpu, 1000)
+			So(err, ShouldBeNil)
+			So(trap.pc, ShouldEqual, 0x040B)
+			So(cpu.peek(0x10), ShouldEqual, 1)
+			So(cpu.peek(0x11), ShouldEqual, 1)
+		})
+
+		Convey("gives up on a jam or when it runs out of cycles", func() {
+			image[0x0400] = 0x02 // JAM
+			_, err := runDormann(newDormannCPU(image), 1000)
+			So(err, ShouldBeError, "jammed at $0400")
+
+			copy(image[0x0400:], []byte{0xe8, 0x4c, 0x00, 0x04}) // INX, JMP $0400
+			_, err = runDormann(newDormannCPU(image), 1000)
+			So(err, ShouldBeError, "didn't trap in 1000 cycles, the pc is $0400")
+		})
+	})
+}
+
+func TestDormann(t *testing.T) {
+	for _, suite := range []struct {
+		file    string
+		success uint16
+	}{
+		{"6502_functional_test.bin", dormannFunctionalSuccess},
+		{"6502_interrupt_test.bin", dormannInterruptSuccess},
+	} {
+		image, err := os.ReadFile(filepath.Join("testdata", suite.file))
+		if err != nil {
+			t.Logf("skipping %v, it's not in testdata", suite.file)
+			continue
+		}
+		for _, accurate := range []bool{false, true} {
+			Convey(fmt.Sprintf("%v passes with accurate %v", suite.file, accurate), t, func() {
+				trap, err := runDormann(newDormannCPU(image, WithAccurate(accurate)), 200_000_000)
+				So(err, ShouldBeNil)
+				So(trap.String(), ShouldStartWith, fmt.Sprintf("trapped at $%04X ", suite.success))
+			})
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Package opcodedata reads the opcode metadata in the cpu package's opcodes.csv and turns it into the Go table and the JSON export.
+package opcodedata
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Variants are the opcode tables in the data file, in the order they're generated.
+var Variants = []string{"nmos", "65c02"}
+
+// funcNames are the names of the generated functions that set each variant's table.
+var funcNames = map[string]string{"nmos": "initializeNMOSOpcodes", "65c02": "initializeCMOSOpcodes"}
+
+// flagOrder is the order flags are listed in, same as the status byte.
+const flagOrder = "NVBDIZC"
+
+// Opcode is one row of the data file.
+type Opcode struct {
+	Opcode    byte   `json:"opcode"`
+	Name      string `json:"name"`
+	Mode      string `json:"mode"`
+	Size      int    `json:"size"`
+	Cycles    int    `json:"cycles"`
+	PageCross bool   `json:"pageCross"`
+	Flags     string `json:"flags"` // the flags it can change in NV-BDIZC order, empty for none
+	Illegal   bool   `json:"illegal"`
+	Unstable  bool   `json:"unstable"`
+}
+
+// Table is a variant's opcodes indexed by opcode.
+type Table [256]Opcode
+
+// Parse reads the data file into a table per variant. Every variant must define each of the 256 opcodes exactly once.
+func Parse(r io.Reader) (map[string]*Table, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 || strings.Join(records[0], ",") != "variant,opcode,name,mode,size,cycles,pagecross,flags,kind" {
+		return nil, fmt.Errorf("missing the header")
+	}
+
+	tables, seen := map[string]*Table{}, map[string]*[256]bool{}
+	for _, v := range Variants {
+		tables[v], seen[v] = &Table{}, &[256]bool{}
+	}
+	for _, rec := range records[1:] {
+		op, variant, err := parseRecord(rec)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", strings.Join(rec, ","), err)
+		}
+		if seen[variant][op.Opcode] {
+			return nil, fmt.Errorf("%v: opcode 0x%02X is defined twice", strings.Join(rec, ","), op.Opcode)
+		}
+		seen[variant][op.Opcode] = true
+		tables[variant][op.Opcode] = op
+	}
+	for _, v := range Variants {
+		for opc, ok := range seen[v] {
+			if !ok {
+				return nil, fmt.Errorf("%v: opcode 0x%02X is missing", v, opc)
+			}
+		}
+	}
+	return tables, nil
+}
+
+func parseRecord(rec []string) (op Opcode, variant string, err error) {
+	variant = rec[0]
+	if _, ok := funcNames[variant]; !ok {
+		return op, "", fmt.Errorf("unknown variant %q", variant)
+	}
+	opc, err := strconv.ParseUint(rec[1], 16, 8)
+	if err != nil {
+		return op, "", err
+	}
+	op.Opcode, op.Name, op.Mode = byte(opc), rec[2], rec[3]
+	if op.Size, err = strconv.Atoi(rec[4]); err != nil {
+		return op, "", err
+	}
+	if op.Cycles, err = strconv.Atoi(rec[5]); err != nil {
+		return op, "", err
+	}
+	if op.PageCross, err = strconv.ParseBool(rec[6]); err != nil {
+		return op, "", err
+	}
+	if rec[7] != "-" {
+		op.Flags = rec[7]
+		if !isSubsequence(op.Flags, flagOrder) {
+			return op, "", fmt.Errorf("flags %q aren't in %v order", op.Flags, flagOrder)
+		}
+	}
+	switch rec[8] {
+	case "legal":
+	case "illegal":
+		op.Illegal = true
+	case "unstable":
+		op.Illegal, op.Unstable = true, true
+	default:
+		return op, "", fmt.Errorf("unknown kind %q", rec[8])
+	}
+	return op, variant, nil
+}
+
+// isSubsequence reports whether every letter of s appears in order, each at most once, in of.
+func isSubsequence(s, of string) bool {
+	for _, r := range s {
+		i := strings.IndexRune(of, r)
+		if i < 0 {
+			return false
+		}
+		of = of[i+1:]
+	}
+	return true
+}
+
+// bitOps are the 65C02's opcodes whose handler takes the bit number at the end of the name.
+var bitOps = regexp.MustCompile(`^(RMB|SMB|BBR|BBS)([0-7])$`)
+
+// handler is the CPU method that runs op: LDA runs cpu.lda and RMB3 runs cpu.rmb(3).
+func handler(op Opcode) string {
+	if m := bitOps.FindStringSubmatch(op.Name); m != nil {
+		return fmt.Sprintf("cpu.%v(%v)", strings.ToLower(m[1]), m[2])
+	}
+	return "cpu." + strings.ToLower(op.Name)
+}
+
+// Go generates the cpu package's opcode tables.
+func Go(tables map[string]*Table) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by opcodegen from opcodes.csv. DO NOT EDIT.\n\npackage cpu\n")
+	for _, v := range Variants {
+		fmt.Fprintf(&b, "\n// %v sets the opcode table of the %v variant.\nfunc (cpu *CPU) %v() {\n\tcpu.opcodes = [256]opcode{\n", funcNames[v], v, funcNames[v])
+		for _, op := range tables[v] {
+			fmt.Fprintf(&b, "0x%02X: {Name: %q, Mode: %v, Size: %v, Cycles: %v, Do: %v", op.Opcode, op.Name, op.Mode, op.Size, op.Cycles, handler(op))
+			if op.PageCross {
+				b.WriteString(", PageCross: true")
+			}
+			if op.Flags != "" {
+				masks := []string{}
+				for _, f := range op.Flags {
+					masks = append(masks, "pos"+string(f))
+				}
+				fmt.Fprintf(&b, ", Flags: %v", strings.Join(masks, " | "))
+			}
+			if op.Illegal {
+				b.WriteString(", Illegal: true")
+			}
+			if op.Unstable {
+				b.WriteString(", Unstable: true")
+			}
+			b.WriteString("},\n")
+		}
+		b.WriteString("}\n}\n")
+	}
+	return format.Source(b.Bytes())
+}
+
+// JSON generates the JSON export of the tables, an object with an array of 256 opcodes per variant.
+func JSON(tables map[string]*Table) ([]byte, error) {
+	out, err := json.MarshalIndent(tables, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
@@ -0,0 +1,44 @@
+// Command opcodegen generates the cpu package's opcode tables and their JSON export from opcodes.csv.
+//
+//	go run ./internal/opcodegen -in opcodes.csv -go opcodes_gen.go -json opcodes.json
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"nes/pkg/cpu/internal/opcodedata"
+)
+
+func main() {
+	in := flag.String("in", "opcodes.csv", "the opcode data file")
+	goOut := flag.String("go", "opcodes_gen.go", "where to write the Go tables")
+	jsonOut := flag.String("json", "opcodes.json", "where to write the JSON export")
+	flag.Parse()
+
+	f, err := os.Open(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	tables, err := opcodedata.Parse(f)
+	if err != nil {
+		log.Fatalf("%v: %v", *in, err)
+	}
+
+	src, err := opcodedata.Go(tables)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*goOut, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+	js, err := opcodedata.JSON(tables)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*jsonOut, js, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
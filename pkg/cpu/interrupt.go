@@ -0,0 +1,115 @@
+package cpu
+
+// The 6502 has three interrupt inputs.
+//
+// NMI is edge triggered: going from released to asserted latches a request that gets serviced even if it's released again.
+// IRQ is level triggered: it's serviced as long as it's asserted when the CPU polls and the I flag is clear.
+// RESET restarts the CPU from the reset vector.
+//
+// The CPU polls NMI and IRQ during the second to last cycle of every instruction and runs the interrupt sequence in place of the next instruction.
+// https://www.nesdev.org/wiki/CPU_interrupts
+
+const (
+	nmiVector  = uint16(0xFFFA)
+	pcInitAddr = uint16(0xFFFC) // the reset vector
+	irqVector  = uint16(0xFFFE) // shared by IRQ and BRK
+)
+
+// SetNMI sets the level of the non-maskable interrupt input.
+// NMI is edge triggered so only going from released to asserted requests an interrupt, holding it asserted doesn't request another.
+func (cpu *CPU) SetNMI(asserted bool) {
+	if asserted && !cpu.nmiLine {
+		cpu.nmiPending = true
+	}
+	cpu.nmiLine = asserted
+}
+
+// SetIRQ sets the level of the interrupt request input.
+// IRQ is level triggered so it keeps requesting interrupts for as long as it's asserted, unless the I flag is set.
+func (cpu *CPU) SetIRQ(asserted bool) {
+	cpu.irqLine = asserted
+}
+
+// interrupt runs the interrupt sequence for a polled NMI or IRQ instead of the next instruction.
+// The returned info is named after the vector the sequence took and has the pc it interrupted.
+func (cpu *CPU) interrupt() StepInfo {
+	start, info := cpu.cycles, StepInfo{PC: cpu.pc, Opcode: cpu.peek(cpu.pc), Interrupt: true}
+	if cpu.accurate {
+		// fetches the next opcode and operand like BRK but throws them away and doesn't increment the pc
+		cpu.read(cpu.pc)
+		cpu.read(cpu.pc)
+	}
+	info.Name, info.Addr = "IRQ", irqVector
+	if cpu.pushInterrupt(false) == nmiVector {
+		info.Name, info.Addr = "NMI", nmiVector
+	}
+	if cpu.accurate {
+		// the sequence doesn't poll and polled is already clear, so the handler's first instruction runs and polls as usual
+		cpu.interrupted = false
+	} else {
+		cpu.cycles += 7
+	}
+	info.Cycles = int(cpu.cycles - start)
+	return info
+}
+
+// pushInterrupt is the part of the interrupt sequence shared by BRK, IRQ and NMI.
+// The pc and status are pushed, I is set and the pc is loaded from the interrupt vector.
+//
+// The B flag in the pushed status is set for BRK and clear for IRQ and NMI.
+// If an NMI shows up before the status is pushed it hijacks the sequence, which then uses the NMI vector but keeps the B flag.
+// The vector that was used is returned.
+func (cpu *CPU) pushInterrupt(brk bool) uint16 {
+	cpu.push16(cpu.pc)
+
+	vector := irqVector
+	if cpu.nmiPending {
+		cpu.nmiPending = false
+		vector = nmiVector
+	}
+
+	status := cpu.status.Get() | pos_
+	if brk {
+		status |= posB
+	} else {
+		status &^= posB
+	}
+	cpu.push(status)
+
+	cpu.status.SetFlag(posI, true)
+	if cpu.variant == Variant65C02 {
+		cpu.status.SetFlag(posD, false) // the 65C02 also clears decimal mode so handlers don't have to
+	}
+	cpu.pc = cpu.read16(vector)
+	cpu.interrupted = true
+	return vector
+}
+
+// reset runs the 6502's reset sequence.
+//
+// It's the interrupt sequence with the writes turned into reads, so S is decremented by 3 without touching the stack.
+// I is set and the pc is loaded from the reset vector, the other registers and flags are left alone.
+// It's the only way out of a JAM.
+func (cpu *CPU) reset() {
+	if cpu.accurate {
+		cpu.read(cpu.pc)
+		cpu.read(cpu.pc)
+		for range 3 {
+			cpu.read(cpu.effStack())
+			cpu.s -= 1
+		}
+	} else {
+		cpu.s -= 3
+		cpu.cycles += 7
+	}
+	cpu.status.SetFlag(posI, true)
+	cpu.status.SetFlag(pos_, true) // bit 5 isn't a real flag and always reads as set
+	if cpu.variant == Variant65C02 {
+		cpu.status.SetFlag(posD, false)
+	}
+	cpu.pc = cpu.read16(pcInitAddr)
+
+	cpu.halted, cpu.waiting = false, false
+	cpu.nmiPending, cpu.polled, cpu.pollI = false, false, true
+	cpu.interrupted = !cpu.accurate // like the interrupt sequence, see [CPU.interrupt]
+}
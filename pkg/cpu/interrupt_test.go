@@ -0,0 +1,339 @@
+package cpu
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestInterrupts(t *testing.T) {
+	Convey("should service interrupts", t, func() {
+		cpu := newCPU()
+		cpu.write16(nmiVector, 0x0400)
+		cpu.write16(pcInitAddr, 0x0200)
+		cpu.write16(irqVector, 0x0500)
+		for i := uint16(0x0200); i < 0x0600; i++ {
+			cpu.write(i, 0xea) // NOPs everywhere
+		}
+		cpu.s = 0xff
+		cpu.pc = 0x0200
+
+		Convey("irq when I is clear", func() {
+			cpu.status.Set(posC)
+			cpu.SetIRQ(true)
+
+			So(stepCycles(cpu.Step()), ShouldEqual, 7)
+			So(cpu.pc, ShouldEqual, 0x0500)
+			So(cpu.status.Flag(posI), ShouldBeTrue)
+			So(cpu.pop(), ShouldEqual, posC|pos_) // no B flag
+			So(cpu.pop16(), ShouldEqual, 0x0200)
+		})
+
+		Convey("irq is masked by I", func() {
+			cpu.status.SetFlag(posI, true)
+			cpu.pollI = true
+			cpu.SetIRQ(true)
+			So(stepCycles(cpu.Step()), ShouldEqual, 2)
+			So(cpu.pc, ShouldEqual, 0x0201)
+		})
+
+		Convey("irq is level triggered", func() {
+			cpu.SetIRQ(true)
+			cpu.Step() // irq
+			cpu.Step() // first instruction of the handler
+			cpu.rti(opDat{})
+			cpu.pollI = cpu.status.Flag(posI)
+			So(cpu.pc, ShouldEqual, 0x0200)
+
+			cpu.Step() // still asserted so it interrupts again
+			So(cpu.pc, ShouldEqual, 0x0500)
+
+			cpu.SetIRQ(false)
+			cpu.Step()
+			cpu.rti(opDat{})
+			cpu.pollI = cpu.status.Flag(posI)
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0201)
+		})
+
+		Convey("nmi ignores I and is edge triggered", func() {
+			cpu.status.SetFlag(posI, true)
+			cpu.pollI = true
+			cpu.SetNMI(true)
+
+			So(stepCycles(cpu.Step()), ShouldEqual, 7)
+			So(cpu.pc, ShouldEqual, 0x0400)
+			So(cpu.pop()&posB, ShouldEqual, 0)
+			cpu.push(0)
+
+			cpu.Step()
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0402) // held asserted doesn't retrigger
+
+			cpu.SetNMI(false)
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0403)
+
+			cpu.SetNMI(true)
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0400)
+		})
+
+		Convey("nmi latches even if released before it's serviced", func() {
+			cpu.SetNMI(true)
+			cpu.SetNMI(false)
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0400)
+		})
+
+		Convey("nmi wins over irq", func() {
+			cpu.SetIRQ(true)
+			cpu.SetNMI(true)
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0400)
+		})
+
+		Convey("the first instruction of a handler always runs", func() {
+			cpu.SetIRQ(true)
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0500)
+			cpu.SetNMI(true)
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0501)
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0400)
+		})
+
+		Convey("cli delays the irq by an instruction", func() {
+			cpu.status.SetFlag(posI, true)
+			cpu.pollI = true
+			cpu.write(0x0200, 0x58) // CLI
+			cpu.SetIRQ(true)
+
+			cpu.Step()
+			So(cpu.status.Flag(posI), ShouldBeFalse)
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0202) // the NOP after the CLI ran
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0500)
+		})
+
+		Convey("sei lets an irq through right after it", func() {
+			cpu.write(0x0200, 0x78) // SEI
+			cpu.Step()
+			cpu.SetIRQ(true) // asserted during the SEI
+
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0500)
+			So(cpu.pop()&posI, ShouldEqual, posI) // the pushed status already has I set
+		})
+
+		Convey("plp delays like cli", func() {
+			cpu.status.SetFlag(posI, true)
+			cpu.pollI = true
+			cpu.push(0x00)
+			cpu.write(0x0200, 0x28) // PLP
+			cpu.SetIRQ(true)
+
+			cpu.Step()
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0202)
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0500)
+		})
+
+		Convey("rti takes effect immediately", func() {
+			cpu.status.SetFlag(posI, true)
+			cpu.pollI = true
+			cpu.push16(0x0300)
+			cpu.push(0x00)
+			cpu.write(0x0200, 0x40) // RTI
+			cpu.SetIRQ(true)
+
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0300)
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0500)
+		})
+
+		Convey("brk pushes B and doesn't poll", func() {
+			cpu.write(0x0200, 0x00) // BRK
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0500)
+			So(cpu.status.Flag(posI), ShouldBeTrue)
+			So(cpu.pop()&posB, ShouldEqual, posB)
+			So(cpu.pop16(), ShouldEqual, 0x0202)
+		})
+
+		Convey("reset", func() {
+			cpu.a, cpu.x, cpu.y, cpu.s = 1, 2, 3, 0x10
+			cpu.status.Set(posC)
+			cpu.SetNMI(true)
+			cpu.write(0x0110, 0x55)
+			cpu.cycles = 0
+
+			cpu.reset()
+			So(cpu.pc, ShouldEqual, 0x0200)
+			So(cpu.s, ShouldEqual, 0x0d)
+			So(cpu.status.Get(), ShouldEqual, posC|posI|pos_)
+			So([]byte{cpu.a, cpu.x, cpu.y}, ShouldResemble, []byte{1, 2, 3})
+			So(cpu.read(0x0110), ShouldEqual, 0x55) // nothing written to the stack
+			So(cpu.cycles, ShouldEqual, 7)
+
+			cpu.Step() // pending nmi is cleared
+			So(cpu.pc, ShouldEqual, 0x0201)
+		})
+
+		Convey("power on reset leaves the stack pointer at fd", func() {
+			cpu.s = 0x00
+			cpu.reset()
+			So(cpu.s, ShouldEqual, 0xfd)
+		})
+	})
+
+	Convey("should poll on the second to last cycle in the accurate executor", t, func() {
+		cpu := newCPU()
+		cpu.write16(nmiVector, 0x0400)
+		cpu.write16(irqVector, 0x0500)
+		for i := uint16(0x0200); i < 0x0600; i++ {
+			cpu.write(i, 0xea)
+		}
+		cpu.s = 0xff
+		cpu.pc = 0x0200
+		cpu.accurate = true
+
+		// assert calls fn on the given cycle (counting from 1) of the next step
+		assert := func(cycle int, fn func()) {
+			n := 0
+			cpu.onCycle = func(uint16, byte, bool) {
+				n++
+				if n == cycle {
+					fn()
+				}
+			}
+		}
+
+		Convey("irq on the penultimate cycle is serviced after the instruction", func() {
+			cpu.write(0x0200, 0xad) // LDA $0300
+			assert(3, func() { cpu.SetIRQ(true) })
+			cpu.Step()
+			So(stepCycles(cpu.Step()), ShouldEqual, 7)
+			So(cpu.pc, ShouldEqual, 0x0500)
+		})
+
+		Convey("irq on the last cycle waits for the next instruction", func() {
+			cpu.write(0x0200, 0xad) // LDA $0300
+			assert(4, func() { cpu.SetIRQ(true) })
+			cpu.Step()
+			So(stepCycles(cpu.Step()), ShouldEqual, 2)
+			So(cpu.pc, ShouldEqual, 0x0204)
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0500)
+		})
+
+		Convey("a taken branch that doesn't cross a page doesn't poll on its last cycle", func() {
+			cpu.status.SetFlag(posZ, true)
+			cpu.write(0x0200, 0xf0) // BEQ +0
+			cpu.write(0x0201, 0x00)
+			assert(2, func() { cpu.SetIRQ(true) })
+			cpu.Step()
+			So(stepCycles(cpu.Step()), ShouldEqual, 7)
+
+			cpu.SetIRQ(false)
+			cpu.status.SetFlag(posI, false)
+			cpu.pc = 0x0200
+			cpu.polled = false
+			assert(3, func() { cpu.SetIRQ(true) })
+			cpu.Step()
+			So(stepCycles(cpu.Step()), ShouldEqual, 2) // the next instruction runs first
+			So(stepCycles(cpu.Step()), ShouldEqual, 7)
+		})
+
+		Convey("sei still lets an irq in", func() {
+			cpu.write(0x0200, 0x78) // SEI
+			assert(1, func() { cpu.SetIRQ(true) })
+			cpu.Step()
+			So(cpu.status.Flag(posI), ShouldBeTrue)
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0500)
+		})
+
+		Convey("nmi hijacks brk", func() {
+			cpu.write(0x0200, 0x00) // BRK
+			assert(4, func() { cpu.SetNMI(true) })
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0400)
+			So(cpu.pop()&posB, ShouldEqual, posB)
+			So(cpu.pop16(), ShouldEqual, 0x0202)
+		})
+
+		Convey("nmi too late to hijack brk waits for the handler's first instruction", func() {
+			cpu.write(0x0200, 0x00) // BRK
+			assert(6, func() { cpu.SetNMI(true) })
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0500)
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0501)
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0400)
+		})
+
+		Convey("the first instruction of a handler always runs", func() {
+			cpu.SetIRQ(true)
+			cpu.Step() // polls the irq
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0500)
+			cpu.SetNMI(true)
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0501)
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0400)
+		})
+
+		Convey("nmi too late to hijack irq waits for the handler's first instruction", func() {
+			cpu.SetIRQ(true)
+			cpu.polled = true
+			assert(6, func() { cpu.SetNMI(true) })
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0500)
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0501)
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0400)
+		})
+
+		Convey("the first instruction after reset polls", func() {
+			cpu.write16(pcInitAddr, 0x0200)
+			cpu.reset()
+			cpu.SetNMI(true)
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0201)
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0400)
+		})
+
+		Convey("nmi hijacks irq", func() {
+			cpu.SetIRQ(true)
+			cpu.polled = true
+			assert(3, func() { cpu.SetNMI(true) })
+			cpu.Step()
+			So(cpu.pc, ShouldEqual, 0x0400)
+			So(cpu.pop()&posB, ShouldEqual, 0)
+		})
+
+		Convey("reset reads instead of writes", func() {
+			cpu.write16(pcInitAddr, 0x0200)
+			cpu.cycles = 0
+			writes := 0
+			cpu.onCycle = func(_ uint16, _ byte, write bool) {
+				if write {
+					writes++
+				}
+			}
+			cpu.reset()
+			So(cpu.cycles, ShouldEqual, 7)
+			So(writes, ShouldEqual, 0)
+			So(cpu.s, ShouldEqual, 0xfc)
+		})
+	})
+}
@@ -0,0 +1,309 @@
+package cpu
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Segment is a run of bytes to load starting at Addr.
+type Segment struct {
+	Addr uint16
+	Data []byte
+}
+
+// Program is a memory image for [CPU.Load].
+type Program struct {
+	Segments []Segment
+	// Reset, NMI and IRQ are the addresses written to the vectors at 0xFFFA-0xFFFF.
+	// A vector that's nil is left as it is, e.g. for an image that has its own vectors.
+	Reset, NMI, IRQ *uint16
+}
+
+// Load writes the program's segments through the bus, sets its vectors and resets the CPU so it starts at the reset vector.
+// Nothing outside the segments and vectors is touched.
+func (cpu *CPU) Load(p *Program) error {
+	for _, seg := range p.Segments {
+		if int(seg.Addr)+len(seg.Data) > 0xFFFF+1 {
+			return fmt.Errorf("segment at 0x%04x of %v bytes runs past the end of memory", seg.Addr, len(seg.Data))
+		}
+	}
+	for _, seg := range p.Segments {
+		for i, dat := range seg.Data {
+			cpu.bus.Write(seg.Addr+uint16(i), dat)
+		}
+	}
+	for _, vec := range []struct {
+		at uint16
+		to *uint16
+	}{{nmiVector, p.NMI}, {pcInitAddr, p.Reset}, {irqVector, p.IRQ}} {
+		if vec.to != nil {
+			cpu.bus.Write(vec.at, byte(*vec.to))
+			cpu.bus.Write(vec.at+1, byte(*vec.to>>8))
+		}
+	}
+	cpu.reset()
+	return nil
+}
+
+// RawProgram is a raw binary loaded at origin, which is also where it starts unless it loads its own reset vector.
+func RawProgram(data []byte, origin uint16) (*Program, error) {
+	p := &Program{}
+	if err := p.add(uint32(origin), data); err != nil {
+		return nil, err
+	}
+	p.defaultReset()
+	return p, nil
+}
+
+// add appends data at addr to the segments, extending the last segment if it carries on from there.
+func (p *Program) add(addr uint32, data []byte) error {
+	if addr+uint32(len(data)) > 0xFFFF+1 {
+		return fmt.Errorf("%v bytes at 0x%x run past the end of memory", len(data), addr)
+	}
+	if n := len(p.Segments); n > 0 {
+		last := &p.Segments[n-1]
+		if uint32(last.Addr)+uint32(len(last.Data)) == addr {
+			last.Data = append(last.Data, data...)
+			return nil
+		}
+	}
+	p.Segments = append(p.Segments, Segment{Addr: uint16(addr), Data: append([]byte(nil), data...)})
+	return nil
+}
+
+// covers reports whether the segments load anything at addr.
+func (p *Program) covers(addr uint16) bool {
+	for _, seg := range p.Segments {
+		if addr >= seg.Addr && int(addr) < int(seg.Addr)+len(seg.Data) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultReset starts the program at its first segment if the file didn't give a start address and doesn't load its own reset vector.
+func (p *Program) defaultReset() {
+	if p.Reset == nil && len(p.Segments) > 0 && !p.covers(pcInitAddr) {
+		start := p.Segments[0].Addr
+		p.Reset = &start
+	}
+}
+
+// records reads the hex encoded records of a HEX or S-record file, which all start with the marker, and calls fn with the text after the marker and its bytes.
+// fn returns true once it's seen the last record. If the input runs out before that the error is [io.ErrUnexpectedEOF].
+func records(r io.Reader, marker string, fn func(text string, rec []byte) (bool, error)) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if !strings.HasPrefix(text, marker) {
+			return fmt.Errorf("line %v: record doesn't start with %q", line, marker)
+		}
+		text = text[len(marker):]
+		hexText := text
+		if marker == "S" && len(text) > 0 {
+			hexText = text[1:] // the record type is a single digit
+		}
+		rec, err := hex.DecodeString(hexText)
+		if err != nil {
+			return fmt.Errorf("line %v: %w", line, err)
+		}
+		done, err := fn(text, rec)
+		if err != nil {
+			return fmt.Errorf("line %v: %w", line, err)
+		}
+		if done {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.ErrUnexpectedEOF
+}
+
+// Intel HEX record types
+const (
+	ihexData         = 0x00
+	ihexEOF          = 0x01
+	ihexSegmentAddr  = 0x02
+	ihexSegmentStart = 0x03
+	ihexLinearAddr   = 0x04
+	ihexLinearStart  = 0x05
+)
+
+// ihexMinRecordSize is the size of a record with no data: the count, address and type, and the checksum.
+const ihexMinRecordSize = 5
+
+// ParseIntelHex reads a program in Intel HEX format. The start address records set the reset vector.
+// https://en.wikipedia.org/wiki/Intel_HEX
+func ParseIntelHex(r io.Reader) (*Program, error) {
+	p := &Program{}
+	var base uint32 // from the extended address records
+	err := records(r, ":", func(_ string, rec []byte) (bool, error) {
+		if len(rec) < ihexMinRecordSize || len(rec) != ihexMinRecordSize+int(rec[0]) {
+			return false, errors.New("bad record length")
+		}
+		var sum byte
+		for _, b := range rec {
+			sum += b
+		}
+		if sum != 0 {
+			return false, errors.New("bad checksum")
+		}
+		addr, dat := uint32(rec[1])<<8|uint32(rec[2]), rec[4:len(rec)-1]
+		switch rec[3] {
+		case ihexData:
+			return false, p.add(base+addr, dat)
+		case ihexEOF:
+			return true, nil
+		case ihexSegmentAddr, ihexLinearAddr:
+			if len(dat) != 2 {
+				return false, errors.New("bad extended address record")
+			}
+			base = uint32(dat[0])<<8 | uint32(dat[1])
+			if rec[3] == ihexSegmentAddr {
+				base <<= 4
+			} else {
+				base <<= 16
+			}
+		case ihexSegmentStart, ihexLinearStart:
+			if len(dat) != 4 {
+				return false, errors.New("bad start address record")
+			}
+			start := uint32(dat[0])<<24 | uint32(dat[1])<<16 | uint32(dat[2])<<8 | uint32(dat[3])
+			if rec[3] == ihexSegmentStart {
+				start = start>>16<<4 + start&0xFFFF // CS:IP
+			}
+			if start > 0xFFFF {
+				return false, fmt.Errorf("start address 0x%x is past the end of memory", start)
+			}
+			reset := uint16(start)
+			p.Reset = &reset
+		default:
+			return false, fmt.Errorf("unknown record type 0x%02x", rec[3])
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("intel hex: %w", err)
+	}
+	p.defaultReset()
+	return p, nil
+}
+
+// ParseSRecord reads a program in Motorola S-record format. The termination record's start address sets the reset vector.
+// https://en.wikipedia.org/wiki/SREC_(file_format)
+func ParseSRecord(r io.Reader) (*Program, error) {
+	p := &Program{}
+	addrSizes := map[byte]int{'0': 2, '1': 2, '2': 3, '3': 4, '5': 2, '6': 3, '7': 4, '8': 3, '9': 2}
+	err := records(r, "S", func(text string, rec []byte) (bool, error) {
+		if text == "" {
+			return false, errors.New("missing record type")
+		}
+		addrSize, ok := addrSizes[text[0]]
+		if !ok {
+			return false, fmt.Errorf("unknown record type S%c", text[0])
+		}
+		if len(rec) < 2+addrSize || len(rec) != 1+int(rec[0]) {
+			return false, errors.New("bad record length")
+		}
+		var sum byte
+		for _, b := range rec {
+			sum += b
+		}
+		if sum != 0xFF {
+			return false, errors.New("bad checksum")
+		}
+		var addr uint32
+		for _, b := range rec[1 : 1+addrSize] {
+			addr = addr<<8 | uint32(b)
+		}
+		dat := rec[1+addrSize : len(rec)-1]
+		switch text[0] {
+		case '1', '2', '3':
+			return false, p.add(addr, dat)
+		case '7', '8', '9':
+			if addr > 0xFFFF {
+				return false, fmt.Errorf("start address 0x%x is past the end of memory", addr)
+			}
+			reset := uint16(addr)
+			p.Reset = &reset
+			return true, nil
+		}
+		return false, nil // the header and record counts don't load anything
+	})
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = nil // the termination record is optional
+	}
+	if err != nil {
+		return nil, fmt.Errorf("s-record: %w", err)
+	}
+	p.defaultReset()
+	return p, nil
+}
+
+// ParseINES reads an iNES ROM and maps its PRG ROM into $8000-$FFFF like mapper 0 does, 16KiB of it mirrored at $C000.
+// Only the first 32KiB is mapped, anything past that needs a mapper. The ROM has its own vectors.
+// https://www.nesdev.org/wiki/INES
+func ParseINES(r io.Reader) (*Program, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 16 || string(data[:4]) != "NES\x1a" {
+		return nil, errors.New("ines: missing header")
+	}
+	prgSize, prg := int(data[4])*0x4000, data[16:]
+	if data[6]&0x04 != 0 { // a 512 byte trainer comes first
+		prg = prg[min(512, len(prg)):]
+	}
+	if prgSize == 0 || len(prg) < prgSize {
+		return nil, fmt.Errorf("ines: PRG ROM is %v bytes but the file only has %v", prgSize, len(prg))
+	}
+	prg = prg[:min(prgSize, 0x8000)]
+	p := &Program{}
+	if err := p.add(0x8000, prg); err != nil {
+		return nil, err
+	}
+	if len(prg) == 0x4000 {
+		if err := p.add(0xC000, prg); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// ParseProgram reads a program in whatever format its name or header says: iNES, Intel HEX (.hex, .ihx),
+// S-records (.s19, .s28, .s37, .srec) or else a raw binary loaded at origin.
+func ParseProgram(name string, data []byte, origin uint16) (*Program, error) {
+	if strings.HasPrefix(string(data), "NES\x1a") {
+		return ParseINES(bytes.NewReader(data))
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".hex", ".ihx":
+		return ParseIntelHex(bytes.NewReader(data))
+	case ".s19", ".s28", ".s37", ".srec":
+		return ParseSRecord(bytes.NewReader(data))
+	}
+	return RawProgram(data, origin)
+}
+
+// ParseAddr parses a hex address the way the commands take them, written as C000, $C000 or 0xC000.
+func ParseAddr(s string) (uint16, error) {
+	hexAddr := strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(s, "$"), "0x"), "0X")
+	addr, err := strconv.ParseUint(hexAddr, 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("bad address %v", s)
+	}
+	return uint16(addr), nil
+}
@@ -0,0 +1,201 @@
+package cpu
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLoad(t *testing.T) {
+	Convey("should load programs", t, func() {
+		cpu := newCPU()
+		program := []byte{0xa9, 0x42, 0x8d, 0x00, 0x03, 0x00} // LDA #$42, STA $0300, BRK
+		vector := func(addr uint16) *uint16 { return &addr }
+
+		Convey("raw binaries load at their origin and start there", func() {
+			cpu.ram()[0x0000], cpu.ram()[0x01ff] = 0x11, 0x22
+			p, err := RawProgram(program, 0x8000)
+			So(err, ShouldBeNil)
+			p.IRQ = vector(0x1234)
+			So(cpu.Load(p), ShouldBeNil)
+
+			So(cpu.ram()[0x8000:0x8006], ShouldResemble, program)
+			So(cpu.ram()[0x0000], ShouldEqual, 0x11) // zero page and the stack are left alone
+			So(cpu.ram()[0x01ff], ShouldEqual, 0x22)
+			So(cpu.ram()[0xFFFC:0xFFFE], ShouldResemble, []byte{0x00, 0x80})
+			So(cpu.ram()[0xFFFE:], ShouldResemble, []byte{0x34, 0x12})
+			So(cpu.ram()[0xFFFA:0xFFFC], ShouldResemble, []byte{0x00, 0x00}) // no NMI handler so it wasn't touched
+
+			So(cpu.pc, ShouldEqual, 0x8000)
+			So(cpu.status.Flag(posI), ShouldBeTrue)
+			cpu.SetBreakpoint(0x1234)
+			stop := cpu.Run(context.Background(), 0)
+			So(stop.Reason, ShouldEqual, StopBreakpoint)
+			So(cpu.ram()[0x0300], ShouldEqual, 0x42)
+		})
+
+		Convey("raw binaries can start at 0", func() {
+			copy(cpu.ram()[0xFFFC:], []byte{0x00, 0x80})
+			p, err := RawProgram(program, 0x0000)
+			So(err, ShouldBeNil)
+			So(cpu.Load(p), ShouldBeNil)
+			So(cpu.ram()[0xFFFC:0xFFFE], ShouldResemble, []byte{0x00, 0x00})
+			So(cpu.pc, ShouldEqual, 0x0000)
+			So(cpu.ram()[0x0000:0x0006], ShouldResemble, program)
+		})
+
+		Convey("raw binaries with their own vectors keep them", func() {
+			image := make([]byte, 0x10000)
+			copy(image[0xFFFA:], []byte{0x00, 0x04, 0x00, 0x02, 0x00, 0x05})
+			p, err := RawProgram(image, 0x0000)
+			So(err, ShouldBeNil)
+			So(p.Reset, ShouldBeNil)
+			So(cpu.Load(p), ShouldBeNil)
+			So(cpu.pc, ShouldEqual, 0x0200)
+			So(cpu.ram()[0xFFFA:], ShouldResemble, []byte{0x00, 0x04, 0x00, 0x02, 0x00, 0x05})
+		})
+
+		Convey("loading resets a halted cpu", func() {
+			cpu.halted = true
+			p, _ := RawProgram(program, 0x0200)
+			So(cpu.Load(p), ShouldBeNil)
+			So(cpu.halted, ShouldBeFalse)
+		})
+
+		Convey("programs can't run past the end of memory", func() {
+			_, err := RawProgram(program, 0xFFFC)
+			So(err, ShouldNotBeNil)
+			So(cpu.Load(&Program{Segments: []Segment{{Addr: 0xFFFF, Data: []byte{1, 2}}}}), ShouldNotBeNil)
+		})
+
+		Convey("intel hex", func() {
+			hexFile := strings.Join([]string{
+				":03020000A9428D83",
+				":03020300000300F5",
+				":020000040000FA", // extended linear address 0
+				":0400000500000200F5",
+				":00000001FF",
+			}, "\n")
+			p, err := ParseIntelHex(strings.NewReader(hexFile))
+			So(err, ShouldBeNil)
+			So(p, ShouldResemble, &Program{Segments: []Segment{{Addr: 0x0200, Data: program}}, Reset: vector(0x0200)})
+
+			So(cpu.Load(p), ShouldBeNil)
+			So(cpu.pc, ShouldEqual, 0x0200)
+		})
+
+		Convey("intel hex without a start address starts at the first segment", func() {
+			p, err := ParseIntelHex(strings.NewReader(":03020300000300F5\n:00000001FF\n"))
+			So(err, ShouldBeNil)
+			So(*p.Reset, ShouldEqual, 0x0203)
+		})
+
+		Convey("an image with its own vectors keeps them", func() {
+			p, err := ParseIntelHex(strings.NewReader(":06FFFA00009000020091DE\n:00000001FF\n"))
+			So(err, ShouldBeNil)
+			So(p.Reset, ShouldBeNil)
+			So(cpu.Load(p), ShouldBeNil)
+			So(cpu.pc, ShouldEqual, 0x0200)
+		})
+
+		Convey("bad intel hex", func() {
+			for hexFile, msg := range map[string]string{
+				":03020000A9428D84\n:00000001FF\n":                  "intel hex: line 1: bad checksum",
+				":03020000A9428D\n":                                 "intel hex: line 1: bad record length",
+				"03020000A9428D83\n":                                `intel hex: line 1: record doesn't start with ":"`,
+				":03020000A9428D83\n":                               "intel hex: " + io.ErrUnexpectedEOF.Error(),
+				":020000040001F9\n:03020000A9428D83\n:00000001FF\n": "intel hex: line 2: 3 bytes at 0x10200 run past the end of memory",
+				":00000006FA\n":                                     "intel hex: line 1: unknown record type 0x06",
+				"\n\n:00000001FF\n:this is after the end of file\n": "",
+			} {
+				_, err := ParseIntelHex(strings.NewReader(hexFile))
+				if msg == "" {
+					So(err, ShouldBeNil)
+				} else {
+					So(err, ShouldBeError, msg)
+				}
+			}
+		})
+
+		Convey("s-records", func() {
+			srec := strings.Join([]string{
+				"S00600004844521B", // header
+				"S1090200A9428D00030079",
+				"S9030200FA",
+			}, "\r\n")
+			p, err := ParseSRecord(strings.NewReader(srec))
+			So(err, ShouldBeNil)
+			So(p, ShouldResemble, &Program{Segments: []Segment{{Addr: 0x0200, Data: program}}, Reset: vector(0x0200)})
+		})
+
+		Convey("s-records with 24 bit addresses", func() {
+			p, err := ParseSRecord(strings.NewReader("S205008000EA90\nS8040080007B\n"))
+			So(err, ShouldBeNil)
+			So(p, ShouldResemble, &Program{Segments: []Segment{{Addr: 0x8000, Data: []byte{0xea}}}, Reset: vector(0x8000)})
+		})
+
+		Convey("s-records don't need a termination record", func() {
+			p, err := ParseSRecord(strings.NewReader("S1090200A9428D00030079\n"))
+			So(err, ShouldBeNil)
+			So(*p.Reset, ShouldEqual, 0x0200)
+		})
+
+		Convey("bad s-records", func() {
+			for srec, msg := range map[string]string{
+				"S1090200A9428D00030078\n": "s-record: line 1: bad checksum",
+				"S1080200A9428D00030079\n": "s-record: line 1: bad record length",
+				"S4030200FA\n":             "s-record: line 1: unknown record type S4",
+				"S\n":                      "s-record: line 1: missing record type",
+				":1090200A9428D00030079\n": `s-record: line 1: record doesn't start with "S"`,
+			} {
+				_, err := ParseSRecord(strings.NewReader(srec))
+				So(err, ShouldBeError, msg)
+			}
+		})
+
+		Convey("ines roms map 16KiB of PRG ROM twice", func() {
+			rom := append([]byte("NES\x1a\x01\x00\x00\x00"), make([]byte, 8+0x4000)...)
+			rom[16], rom[16+0x3fff] = 0xa9, 0x80
+			p, err := ParseINES(bytes.NewReader(rom))
+			So(err, ShouldBeNil)
+			So(p.Segments, ShouldHaveLength, 1) // the mirror carries straight on from the first copy
+			So(p.Segments[0].Addr, ShouldEqual, 0x8000)
+			So(p.Segments[0].Data, ShouldHaveLength, 0x8000)
+			So(p.Segments[0].Data[0x4000], ShouldEqual, 0xa9)
+			So(p.Segments[0].Data[0x7fff], ShouldEqual, 0x80)
+			So(p.Reset, ShouldBeNil)
+
+			rom[6] = 0x04 // a trainer that isn't there
+			_, err = ParseINES(bytes.NewReader(rom))
+			So(err, ShouldBeError, "ines: PRG ROM is 16384 bytes but the file only has 15872")
+			_, err = ParseINES(strings.NewReader("NES"))
+			So(err, ShouldBeError, "ines: missing header")
+		})
+
+		Convey("addresses are hex with or without a prefix", func() {
+			for _, s := range []string{"C000", "$c000", "0xC000", "0XC000"} {
+				addr, err := ParseAddr(s)
+				So(err, ShouldBeNil)
+				So(addr, ShouldEqual, 0xC000)
+			}
+			_, err := ParseAddr("10000")
+			So(err, ShouldBeError, "bad address 10000")
+			_, err = ParseAddr("$")
+			So(err, ShouldBeError, "bad address $")
+		})
+
+		Convey("programs are parsed by their name or header", func() {
+			p, err := ParseProgram("prog.srec", []byte("S1090200A9428D00030079\n"), 0)
+			So(err, ShouldBeNil)
+			So(p.Segments[0].Addr, ShouldEqual, 0x0200)
+
+			p, err = ParseProgram("prog.bin", program, 0xC000)
+			So(err, ShouldBeNil)
+			So(*p.Reset, ShouldEqual, 0xC000)
+		})
+	})
+}
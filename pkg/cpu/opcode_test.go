@@ -0,0 +1,100 @@
+package cpu
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"os"
+	"testing"
+
+	"nes/pkg/cpu/internal/opcodedata"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// modeSizes is the size of an instruction in each addressing mode.
+var modeSizes = map[string]int{
+	"implicit": 1, "accumulator": 1, "immediate": 2, "zeroPage": 2, "zeroPageX": 2, "zeroPageY": 2, "relative": 2,
+	"absolute": 3, "absoluteX": 3, "absoluteY": 3, "indirect": 3, "indirectX": 2, "indirectY": 2,
+	"zeroPageIndirect": 2, "absoluteIndirectX": 3, "zeroPageRelative": 3,
+}
+
+func TestOpcodeData(t *testing.T) {
+	Convey("opcodes.csv", t, func() {
+		f, err := os.Open("opcodes.csv")
+		So(err, ShouldBeNil)
+		defer f.Close()
+		tables, err := opcodedata.Parse(f)
+		So(err, ShouldBeNil)
+
+		Convey("generated files are up to date, run go generate if not", func() {
+			src, err := opcodedata.Go(tables)
+			So(err, ShouldBeNil)
+			gen, err := os.ReadFile("opcodes_gen.go")
+			So(err, ShouldBeNil)
+			So(bytes.Equal(src, gen), ShouldBeTrue)
+
+			js, err := opcodedata.JSON(tables)
+			So(err, ShouldBeNil)
+			gen, err = os.ReadFile("opcodes.json")
+			So(err, ShouldBeNil)
+			So(bytes.Equal(js, gen), ShouldBeTrue)
+		})
+
+		Convey("rows make sense", func() {
+			for _, v := range opcodedata.Variants {
+				for _, op := range tables[v] {
+					size, ok := modeSizes[op.Mode]
+					So(ok, ShouldBeTrue)
+					if op.Name != "BRK" { // BRK skips a padding byte
+						So(size, ShouldEqual, op.Size)
+					}
+					if op.PageCross {
+						So(op.Mode, ShouldBeIn, "absoluteX", "absoluteY", "indirectY")
+					}
+					if op.Unstable {
+						So(op.Illegal, ShouldBeTrue)
+					}
+				}
+			}
+		})
+
+		Convey("matches the tables the cpu runs", func() {
+			for v, name := range map[Variant]string{Variant2A03: "nmos", VariantNMOS: "nmos", Variant65C02: "65c02"} {
+				infos := Opcodes(v)
+				cpu := New(WithVariant(v))
+				for i, op := range tables[name] {
+					So(cpu.opcodes[i].Do, ShouldNotBeNil)
+					So(infos[i], ShouldResemble, OpcodeInfo(op))
+				}
+			}
+		})
+
+		Convey("opcodes only change the flags they list", func() {
+			rng := rand.New(rand.NewSource(1))
+			wrong := []string{}
+			for _, v := range []Variant{VariantNMOS, Variant65C02} {
+				cpu := New(WithVariant(v))
+				rng.Read(cpu.ram()[:])
+				for opc, op := range Opcodes(v) {
+					if op.Name == "JAM" || op.Name == "STP" || op.Name == "WAI" {
+						continue
+					}
+					for range 32 {
+						rng.Read(cpu.ram()[:0x0300]) // zero page, the stack and the operands
+						cpu.ram()[0x0200] = byte(opc)
+						cpu.pc, cpu.a, cpu.x, cpu.y, cpu.s = 0x0200, byte(rng.Intn(256)), byte(rng.Intn(256)), byte(rng.Intn(256)), byte(rng.Intn(256))
+						cpu.status = Status(rng.Intn(256))
+						before := cpu.status
+						cpu.Step()
+						changed := byte(before^cpu.status) &^ (posB | pos_)
+						if changed&^cpu.opcodes[opc].Flags != 0 {
+							wrong = append(wrong, fmt.Sprintf("%v %02X %v changed %v", v, opc, op.Name, Status(changed)))
+						}
+					}
+				}
+			}
+			So(wrong, ShouldBeEmpty)
+		})
+	})
+}
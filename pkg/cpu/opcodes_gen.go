@@ -0,0 +1,527 @@
+// Code generated by opcodegen from opcodes.csv. DO NOT EDIT.
+
+package cpu
+
+// initializeNMOSOpcodes sets the opcode table of the nmos variant.
+func (cpu *CPU) initializeNMOSOpcodes() {
+	cpu.opcodes = [256]opcode{
+		0x00: {Name: "BRK", Mode: implicit, Size: 2, Cycles: 7, Do: cpu.brk, Flags: posI},
+		0x01: {Name: "ORA", Mode: indirectX, Size: 2, Cycles: 6, Do: cpu.ora, Flags: posN | posZ},
+		0x02: {Name: "JAM", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.jam, Illegal: true},
+		0x03: {Name: "SLO", Mode: indirectX, Size: 2, Cycles: 8, Do: cpu.slo, Flags: posN | posZ | posC, Illegal: true},
+		0x04: {Name: "NOP", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.nop, Illegal: true},
+		0x05: {Name: "ORA", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.ora, Flags: posN | posZ},
+		0x06: {Name: "ASL", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.asl, Flags: posN | posZ | posC},
+		0x07: {Name: "SLO", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.slo, Flags: posN | posZ | posC, Illegal: true},
+		0x08: {Name: "PHP", Mode: implicit, Size: 1, Cycles: 3, Do: cpu.php},
+		0x09: {Name: "ORA", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.ora, Flags: posN | posZ},
+		0x0A: {Name: "ASL", Mode: accumulator, Size: 1, Cycles: 2, Do: cpu.asl, Flags: posN | posZ | posC},
+		0x0B: {Name: "ANC", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.anc, Flags: posN | posZ | posC, Illegal: true},
+		0x0C: {Name: "NOP", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.nop, Illegal: true},
+		0x0D: {Name: "ORA", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.ora, Flags: posN | posZ},
+		0x0E: {Name: "ASL", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.asl, Flags: posN | posZ | posC},
+		0x0F: {Name: "SLO", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.slo, Flags: posN | posZ | posC, Illegal: true},
+		0x10: {Name: "BPL", Mode: relative, Size: 2, Cycles: 2, Do: cpu.bpl},
+		0x11: {Name: "ORA", Mode: indirectY, Size: 2, Cycles: 5, Do: cpu.ora, PageCross: true, Flags: posN | posZ},
+		0x12: {Name: "JAM", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.jam, Illegal: true},
+		0x13: {Name: "SLO", Mode: indirectY, Size: 2, Cycles: 8, Do: cpu.slo, Flags: posN | posZ | posC, Illegal: true},
+		0x14: {Name: "NOP", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.nop, Illegal: true},
+		0x15: {Name: "ORA", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.ora, Flags: posN | posZ},
+		0x16: {Name: "ASL", Mode: zeroPageX, Size: 2, Cycles: 6, Do: cpu.asl, Flags: posN | posZ | posC},
+		0x17: {Name: "SLO", Mode: zeroPageX, Size: 2, Cycles: 6, Do: cpu.slo, Flags: posN | posZ | posC, Illegal: true},
+		0x18: {Name: "CLC", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.clc, Flags: posC},
+		0x19: {Name: "ORA", Mode: absoluteY, Size: 3, Cycles: 4, Do: cpu.ora, PageCross: true, Flags: posN | posZ},
+		0x1A: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.nop, Illegal: true},
+		0x1B: {Name: "SLO", Mode: absoluteY, Size: 3, Cycles: 7, Do: cpu.slo, Flags: posN | posZ | posC, Illegal: true},
+		0x1C: {Name: "NOP", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.nop, PageCross: true, Illegal: true},
+		0x1D: {Name: "ORA", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.ora, PageCross: true, Flags: posN | posZ},
+		0x1E: {Name: "ASL", Mode: absoluteX, Size: 3, Cycles: 7, Do: cpu.asl, Flags: posN | posZ | posC},
+		0x1F: {Name: "SLO", Mode: absoluteX, Size: 3, Cycles: 7, Do: cpu.slo, Flags: posN | posZ | posC, Illegal: true},
+		0x20: {Name: "JSR", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.jsr},
+		0x21: {Name: "AND", Mode: indirectX, Size: 2, Cycles: 6, Do: cpu.and, Flags: posN | posZ},
+		0x22: {Name: "JAM", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.jam, Illegal: true},
+		0x23: {Name: "RLA", Mode: indirectX, Size: 2, Cycles: 8, Do: cpu.rla, Flags: posN | posZ | posC, Illegal: true},
+		0x24: {Name: "BIT", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.bit, Flags: posN | posV | posZ},
+		0x25: {Name: "AND", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.and, Flags: posN | posZ},
+		0x26: {Name: "ROL", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.rol, Flags: posN | posZ | posC},
+		0x27: {Name: "RLA", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.rla, Flags: posN | posZ | posC, Illegal: true},
+		0x28: {Name: "PLP", Mode: implicit, Size: 1, Cycles: 4, Do: cpu.plp, Flags: posN | posV | posD | posI | posZ | posC},
+		0x29: {Name: "AND", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.and, Flags: posN | posZ},
+		0x2A: {Name: "ROL", Mode: accumulator, Size: 1, Cycles: 2, Do: cpu.rol, Flags: posN | posZ | posC},
+		0x2B: {Name: "ANC", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.anc, Flags: posN | posZ | posC, Illegal: true},
+		0x2C: {Name: "BIT", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.bit, Flags: posN | posV | posZ},
+		0x2D: {Name: "AND", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.and, Flags: posN | posZ},
+		0x2E: {Name: "ROL", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.rol, Flags: posN | posZ | posC},
+		0x2F: {Name: "RLA", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.rla, Flags: posN | posZ | posC, Illegal: true},
+		0x30: {Name: "BMI", Mode: relative, Size: 2, Cycles: 2, Do: cpu.bmi},
+		0x31: {Name: "AND", Mode: indirectY, Size: 2, Cycles: 5, Do: cpu.and, PageCross: true, Flags: posN | posZ},
+		0x32: {Name: "JAM", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.jam, Illegal: true},
+		0x33: {Name: "RLA", Mode: indirectY, Size: 2, Cycles: 8, Do: cpu.rla, Flags: posN | posZ | posC, Illegal: true},
+		0x34: {Name: "NOP", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.nop, Illegal: true},
+		0x35: {Name: "AND", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.and, Flags: posN | posZ},
+		0x36: {Name: "ROL", Mode: zeroPageX, Size: 2, Cycles: 6, Do: cpu.rol, Flags: posN | posZ | posC},
+		0x37: {Name: "RLA", Mode: zeroPageX, Size: 2, Cycles: 6, Do: cpu.rla, Flags: posN | posZ | posC, Illegal: true},
+		0x38: {Name: "SEC", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.sec, Flags: posC},
+		0x39: {Name: "AND", Mode: absoluteY, Size: 3, Cycles: 4, Do: cpu.and, PageCross: true, Flags: posN | posZ},
+		0x3A: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.nop, Illegal: true},
+		0x3B: {Name: "RLA", Mode: absoluteY, Size: 3, Cycles: 7, Do: cpu.rla, Flags: posN | posZ | posC, Illegal: true},
+		0x3C: {Name: "NOP", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.nop, PageCross: true, Illegal: true},
+		0x3D: {Name: "AND", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.and, PageCross: true, Flags: posN | posZ},
+		0x3E: {Name: "ROL", Mode: absoluteX, Size: 3, Cycles: 7, Do: cpu.rol, Flags: posN | posZ | posC},
+		0x3F: {Name: "RLA", Mode: absoluteX, Size: 3, Cycles: 7, Do: cpu.rla, Flags: posN | posZ | posC, Illegal: true},
+		0x40: {Name: "RTI", Mode: implicit, Size: 1, Cycles: 6, Do: cpu.rti, Flags: posN | posV | posD | posI | posZ | posC},
+		0x41: {Name: "EOR", Mode: indirectX, Size: 2, Cycles: 6, Do: cpu.eor, Flags: posN | posZ},
+		0x42: {Name: "JAM", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.jam, Illegal: true},
+		0x43: {Name: "SRE", Mode: indirectX, Size: 2, Cycles: 8, Do: cpu.sre, Flags: posN | posZ | posC, Illegal: true},
+		0x44: {Name: "NOP", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.nop, Illegal: true},
+		0x45: {Name: "EOR", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.eor, Flags: posN | posZ},
+		0x46: {Name: "LSR", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.lsr, Flags: posN | posZ | posC},
+		0x47: {Name: "SRE", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.sre, Flags: posN | posZ | posC, Illegal: true},
+		0x48: {Name: "PHA", Mode: implicit, Size: 1, Cycles: 3, Do: cpu.pha},
+		0x49: {Name: "EOR", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.eor, Flags: posN | posZ},
+		0x4A: {Name: "LSR", Mode: accumulator, Size: 1, Cycles: 2, Do: cpu.lsr, Flags: posN | posZ | posC},
+		0x4B: {Name: "ALR", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.alr, Flags: posN | posZ | posC, Illegal: true},
+		0x4C: {Name: "JMP", Mode: absolute, Size: 3, Cycles: 3, Do: cpu.jmp},
+		0x4D: {Name: "EOR", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.eor, Flags: posN | posZ},
+		0x4E: {Name: "LSR", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.lsr, Flags: posN | posZ | posC},
+		0x4F: {Name: "SRE", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.sre, Flags: posN | posZ | posC, Illegal: true},
+		0x50: {Name: "BVC", Mode: relative, Size: 2, Cycles: 2, Do: cpu.bvc},
+		0x51: {Name: "EOR", Mode: indirectY, Size: 2, Cycles: 5, Do: cpu.eor, PageCross: true, Flags: posN | posZ},
+		0x52: {Name: "JAM", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.jam, Illegal: true},
+		0x53: {Name: "SRE", Mode: indirectY, Size: 2, Cycles: 8, Do: cpu.sre, Flags: posN | posZ | posC, Illegal: true},
+		0x54: {Name: "NOP", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.nop, Illegal: true},
+		0x55: {Name: "EOR", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.eor, Flags: posN | posZ},
+		0x56: {Name: "LSR", Mode: zeroPageX, Size: 2, Cycles: 6, Do: cpu.lsr, Flags: posN | posZ | posC},
+		0x57: {Name: "SRE", Mode: zeroPageX, Size: 2, Cycles: 6, Do: cpu.sre, Flags: posN | posZ | posC, Illegal: true},
+		0x58: {Name: "CLI", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.cli, Flags: posI},
+		0x59: {Name: "EOR", Mode: absoluteY, Size: 3, Cycles: 4, Do: cpu.eor, PageCross: true, Flags: posN | posZ},
+		0x5A: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.nop, Illegal: true},
+		0x5B: {Name: "SRE", Mode: absoluteY, Size: 3, Cycles: 7, Do: cpu.sre, Flags: posN | posZ | posC, Illegal: true},
+		0x5C: {Name: "NOP", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.nop, PageCross: true, Illegal: true},
+		0x5D: {Name: "EOR", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.eor, PageCross: true, Flags: posN | posZ},
+		0x5E: {Name: "LSR", Mode: absoluteX, Size: 3, Cycles: 7, Do: cpu.lsr, Flags: posN | posZ | posC},
+		0x5F: {Name: "SRE", Mode: absoluteX, Size: 3, Cycles: 7, Do: cpu.sre, Flags: posN | posZ | posC, Illegal: true},
+		0x60: {Name: "RTS", Mode: implicit, Size: 1, Cycles: 6, Do: cpu.rts},
+		0x61: {Name: "ADC", Mode: indirectX, Size: 2, Cycles: 6, Do: cpu.adc, Flags: posN | posV | posZ | posC},
+		0x62: {Name: "JAM", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.jam, Illegal: true},
+		0x63: {Name: "RRA", Mode: indirectX, Size: 2, Cycles: 8, Do: cpu.rra, Flags: posN | posV | posZ | posC, Illegal: true},
+		0x64: {Name: "NOP", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.nop, Illegal: true},
+		0x65: {Name: "ADC", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.adc, Flags: posN | posV | posZ | posC},
+		0x66: {Name: "ROR", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.ror, Flags: posN | posZ | posC},
+		0x67: {Name: "RRA", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.rra, Flags: posN | posV | posZ | posC, Illegal: true},
+		0x68: {Name: "PLA", Mode: implicit, Size: 1, Cycles: 4, Do: cpu.pla, Flags: posN | posZ},
+		0x69: {Name: "ADC", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.adc, Flags: posN | posV | posZ | posC},
+		0x6A: {Name: "ROR", Mode: accumulator, Size: 1, Cycles: 2, Do: cpu.ror, Flags: posN | posZ | posC},
+		0x6B: {Name: "ARR", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.arr, Flags: posN | posV | posZ | posC, Illegal: true},
+		0x6C: {Name: "JMP", Mode: indirect, Size: 3, Cycles: 5, Do: cpu.jmp},
+		0x6D: {Name: "ADC", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.adc, Flags: posN | posV | posZ | posC},
+		0x6E: {Name: "ROR", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.ror, Flags: posN | posZ | posC},
+		0x6F: {Name: "RRA", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.rra, Flags: posN | posV | posZ | posC, Illegal: true},
+		0x70: {Name: "BVS", Mode: relative, Size: 2, Cycles: 2, Do: cpu.bvs},
+		0x71: {Name: "ADC", Mode: indirectY, Size: 2, Cycles: 5, Do: cpu.adc, PageCross: true, Flags: posN | posV | posZ | posC},
+		0x72: {Name: "JAM", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.jam, Illegal: true},
+		0x73: {Name: "RRA", Mode: indirectY, Size: 2, Cycles: 8, Do: cpu.rra, Flags: posN | posV | posZ | posC, Illegal: true},
+		0x74: {Name: "NOP", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.nop, Illegal: true},
+		0x75: {Name: "ADC", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.adc, Flags: posN | posV | posZ | posC},
+		0x76: {Name: "ROR", Mode: zeroPageX, Size: 2, Cycles: 6, Do: cpu.ror, Flags: posN | posZ | posC},
+		0x77: {Name: "RRA", Mode: zeroPageX, Size: 2, Cycles: 6, Do: cpu.rra, Flags: posN | posV | posZ | posC, Illegal: true},
+		0x78: {Name: "SEI", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.sei, Flags: posI},
+		0x79: {Name: "ADC", Mode: absoluteY, Size: 3, Cycles: 4, Do: cpu.adc, PageCross: true, Flags: posN | posV | posZ | posC},
+		0x7A: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.nop, Illegal: true},
+		0x7B: {Name: "RRA", Mode: absoluteY, Size: 3, Cycles: 7, Do: cpu.rra, Flags: posN | posV | posZ | posC, Illegal: true},
+		0x7C: {Name: "NOP", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.nop, PageCross: true, Illegal: true},
+		0x7D: {Name: "ADC", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.adc, PageCross: true, Flags: posN | posV | posZ | posC},
+		0x7E: {Name: "ROR", Mode: absoluteX, Size: 3, Cycles: 7, Do: cpu.ror, Flags: posN | posZ | posC},
+		0x7F: {Name: "RRA", Mode: absoluteX, Size: 3, Cycles: 7, Do: cpu.rra, Flags: posN | posV | posZ | posC, Illegal: true},
+		0x80: {Name: "NOP", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.nop, Illegal: true},
+		0x81: {Name: "STA", Mode: indirectX, Size: 2, Cycles: 6, Do: cpu.sta},
+		0x82: {Name: "NOP", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.nop, Illegal: true},
+		0x83: {Name: "SAX", Mode: indirectX, Size: 2, Cycles: 6, Do: cpu.sax, Illegal: true},
+		0x84: {Name: "STY", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.sty},
+		0x85: {Name: "STA", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.sta},
+		0x86: {Name: "STX", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.stx},
+		0x87: {Name: "SAX", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.sax, Illegal: true},
+		0x88: {Name: "DEY", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.dey, Flags: posN | posZ},
+		0x89: {Name: "NOP", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.nop, Illegal: true},
+		0x8A: {Name: "TXA", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.txa, Flags: posN | posZ},
+		0x8B: {Name: "ANE", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.ane, Flags: posN | posZ, Illegal: true, Unstable: true},
+		0x8C: {Name: "STY", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.sty},
+		0x8D: {Name: "STA", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.sta},
+		0x8E: {Name: "STX", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.stx},
+		0x8F: {Name: "SAX", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.sax, Illegal: true},
+		0x90: {Name: "BCC", Mode: relative, Size: 2, Cycles: 2, Do: cpu.bcc},
+		0x91: {Name: "STA", Mode: indirectY, Size: 2, Cycles: 6, Do: cpu.sta},
+		0x92: {Name: "JAM", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.jam, Illegal: true},
+		0x93: {Name: "SHA", Mode: indirectY, Size: 2, Cycles: 6, Do: cpu.sha, Illegal: true, Unstable: true},
+		0x94: {Name: "STY", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.sty},
+		0x95: {Name: "STA", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.sta},
+		0x96: {Name: "STX", Mode: zeroPageY, Size: 2, Cycles: 4, Do: cpu.stx},
+		0x97: {Name: "SAX", Mode: zeroPageY, Size: 2, Cycles: 4, Do: cpu.sax, Illegal: true},
+		0x98: {Name: "TYA", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.tya, Flags: posN | posZ},
+		0x99: {Name: "STA", Mode: absoluteY, Size: 3, Cycles: 5, Do: cpu.sta},
+		0x9A: {Name: "TXS", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.txs},
+		0x9B: {Name: "TAS", Mode: absoluteY, Size: 3, Cycles: 5, Do: cpu.tas, Illegal: true, Unstable: true},
+		0x9C: {Name: "SHY", Mode: absoluteX, Size: 3, Cycles: 5, Do: cpu.shy, Illegal: true, Unstable: true},
+		0x9D: {Name: "STA", Mode: absoluteX, Size: 3, Cycles: 5, Do: cpu.sta},
+		0x9E: {Name: "SHX", Mode: absoluteY, Size: 3, Cycles: 5, Do: cpu.shx, Illegal: true, Unstable: true},
+		0x9F: {Name: "SHA", Mode: absoluteY, Size: 3, Cycles: 5, Do: cpu.sha, Illegal: true, Unstable: true},
+		0xA0: {Name: "LDY", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.ldy, Flags: posN | posZ},
+		0xA1: {Name: "LDA", Mode: indirectX, Size: 2, Cycles: 6, Do: cpu.lda, Flags: posN | posZ},
+		0xA2: {Name: "LDX", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.ldx, Flags: posN | posZ},
+		0xA3: {Name: "LAX", Mode: indirectX, Size: 2, Cycles: 6, Do: cpu.lax, Flags: posN | posZ, Illegal: true},
+		0xA4: {Name: "LDY", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.ldy, Flags: posN | posZ},
+		0xA5: {Name: "LDA", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.lda, Flags: posN | posZ},
+		0xA6: {Name: "LDX", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.ldx, Flags: posN | posZ},
+		0xA7: {Name: "LAX", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.lax, Flags: posN | posZ, Illegal: true},
+		0xA8: {Name: "TAY", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.tay, Flags: posN | posZ},
+		0xA9: {Name: "LDA", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.lda, Flags: posN | posZ},
+		0xAA: {Name: "TAX", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.tax, Flags: posN | posZ},
+		0xAB: {Name: "LXA", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.lxa, Flags: posN | posZ, Illegal: true, Unstable: true},
+		0xAC: {Name: "LDY", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.ldy, Flags: posN | posZ},
+		0xAD: {Name: "LDA", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.lda, Flags: posN | posZ},
+		0xAE: {Name: "LDX", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.ldx, Flags: posN | posZ},
+		0xAF: {Name: "LAX", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.lax, Flags: posN | posZ, Illegal: true},
+		0xB0: {Name: "BCS", Mode: relative, Size: 2, Cycles: 2, Do: cpu.bcs},
+		0xB1: {Name: "LDA", Mode: indirectY, Size: 2, Cycles: 5, Do: cpu.lda, PageCross: true, Flags: posN | posZ},
+		0xB2: {Name: "JAM", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.jam, Illegal: true},
+		0xB3: {Name: "LAX", Mode: indirectY, Size: 2, Cycles: 5, Do: cpu.lax, PageCross: true, Flags: posN | posZ, Illegal: true},
+		0xB4: {Name: "LDY", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.ldy, Flags: posN | posZ},
+		0xB5: {Name: "LDA", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.lda, Flags: posN | posZ},
+		0xB6: {Name: "LDX", Mode: zeroPageY, Size: 2, Cycles: 4, Do: cpu.ldx, Flags: posN | posZ},
+		0xB7: {Name: "LAX", Mode: zeroPageY, Size: 2, Cycles: 4, Do: cpu.lax, Flags: posN | posZ, Illegal: true},
+		0xB8: {Name: "CLV", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.clv, Flags: posV},
+		0xB9: {Name: "LDA", Mode: absoluteY, Size: 3, Cycles: 4, Do: cpu.lda, PageCross: true, Flags: posN | posZ},
+		0xBA: {Name: "TSX", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.tsx, Flags: posN | posZ},
+		0xBB: {Name: "LAS", Mode: absoluteY, Size: 3, Cycles: 4, Do: cpu.las, PageCross: true, Flags: posN | posZ, Illegal: true},
+		0xBC: {Name: "LDY", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.ldy, PageCross: true, Flags: posN | posZ},
+		0xBD: {Name: "LDA", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.lda, PageCross: true, Flags: posN | posZ},
+		0xBE: {Name: "LDX", Mode: absoluteY, Size: 3, Cycles: 4, Do: cpu.ldx, PageCross: true, Flags: posN | posZ},
+		0xBF: {Name: "LAX", Mode: absoluteY, Size: 3, Cycles: 4, Do: cpu.lax, PageCross: true, Flags: posN | posZ, Illegal: true},
+		0xC0: {Name: "CPY", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.cpy, Flags: posN | posZ | posC},
+		0xC1: {Name: "CMP", Mode: indirectX, Size: 2, Cycles: 6, Do: cpu.cmp, Flags: posN | posZ | posC},
+		0xC2: {Name: "NOP", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.nop, Illegal: true},
+		0xC3: {Name: "DCP", Mode: indirectX, Size: 2, Cycles: 8, Do: cpu.dcp, Flags: posN | posZ | posC, Illegal: true},
+		0xC4: {Name: "CPY", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.cpy, Flags: posN | posZ | posC},
+		0xC5: {Name: "CMP", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.cmp, Flags: posN | posZ | posC},
+		0xC6: {Name: "DEC", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.dec, Flags: posN | posZ},
+		0xC7: {Name: "DCP", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.dcp, Flags: posN | posZ | posC, Illegal: true},
+		0xC8: {Name: "INY", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.iny, Flags: posN | posZ},
+		0xC9: {Name: "CMP", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.cmp, Flags: posN | posZ | posC},
+		0xCA: {Name: "DEX", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.dex, Flags: posN | posZ},
+		0xCB: {Name: "SBX", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.sbx, Flags: posN | posZ | posC, Illegal: true},
+		0xCC: {Name: "CPY", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.cpy, Flags: posN | posZ | posC},
+		0xCD: {Name: "CMP", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.cmp, Flags: posN | posZ | posC},
+		0xCE: {Name: "DEC", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.dec, Flags: posN | posZ},
+		0xCF: {Name: "DCP", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.dcp, Flags: posN | posZ | posC, Illegal: true},
+		0xD0: {Name: "BNE", Mode: relative, Size: 2, Cycles: 2, Do: cpu.bne},
+		0xD1: {Name: "CMP", Mode: indirectY, Size: 2, Cycles: 5, Do: cpu.cmp, PageCross: true, Flags: posN | posZ | posC},
+		0xD2: {Name: "JAM", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.jam, Illegal: true},
+		0xD3: {Name: "DCP", Mode: indirectY, Size: 2, Cycles: 8, Do: cpu.dcp, Flags: posN | posZ | posC, Illegal: true},
+		0xD4: {Name: "NOP", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.nop, Illegal: true},
+		0xD5: {Name: "CMP", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.cmp, Flags: posN | posZ | posC},
+		0xD6: {Name: "DEC", Mode: zeroPageX, Size: 2, Cycles: 6, Do: cpu.dec, Flags: posN | posZ},
+		0xD7: {Name: "DCP", Mode: zeroPageX, Size: 2, Cycles: 6, Do: cpu.dcp, Flags: posN | posZ | posC, Illegal: true},
+		0xD8: {Name: "CLD", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.cld, Flags: posD},
+		0xD9: {Name: "CMP", Mode: absoluteY, Size: 3, Cycles: 4, Do: cpu.cmp, PageCross: true, Flags: posN | posZ | posC},
+		0xDA: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.nop, Illegal: true},
+		0xDB: {Name: "DCP", Mode: absoluteY, Size: 3, Cycles: 7, Do: cpu.dcp, Flags: posN | posZ | posC, Illegal: true},
+		0xDC: {Name: "NOP", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.nop, PageCross: true, Illegal: true},
+		0xDD: {Name: "CMP", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.cmp, PageCross: true, Flags: posN | posZ | posC},
+		0xDE: {Name: "DEC", Mode: absoluteX, Size: 3, Cycles: 7, Do: cpu.dec, Flags: posN | posZ},
+		0xDF: {Name: "DCP", Mode: absoluteX, Size: 3, Cycles: 7, Do: cpu.dcp, Flags: posN | posZ | posC, Illegal: true},
+		0xE0: {Name: "CPX", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.cpx, Flags: posN | posZ | posC},
+		0xE1: {Name: "SBC", Mode: indirectX, Size: 2, Cycles: 6, Do: cpu.sbc, Flags: posN | posV | posZ | posC},
+		0xE2: {Name: "NOP", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.nop, Illegal: true},
+		0xE3: {Name: "ISC", Mode: indirectX, Size: 2, Cycles: 8, Do: cpu.isc, Flags: posN | posV | posZ | posC, Illegal: true},
+		0xE4: {Name: "CPX", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.cpx, Flags: posN | posZ | posC},
+		0xE5: {Name: "SBC", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.sbc, Flags: posN | posV | posZ | posC},
+		0xE6: {Name: "INC", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.inc, Flags: posN | posZ},
+		0xE7: {Name: "ISC", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.isc, Flags: posN | posV | posZ | posC, Illegal: true},
+		0xE8: {Name: "INX", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.inx, Flags: posN | posZ},
+		0xE9: {Name: "SBC", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.sbc, Flags: posN | posV | posZ | posC},
+		0xEA: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.nop},
+		0xEB: {Name: "USBC", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.usbc, Flags: posN | posV | posZ | posC, Illegal: true},
+		0xEC: {Name: "CPX", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.cpx, Flags: posN | posZ | posC},
+		0xED: {Name: "SBC", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.sbc, Flags: posN | posV | posZ | posC},
+		0xEE: {Name: "INC", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.inc, Flags: posN | posZ},
+		0xEF: {Name: "ISC", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.isc, Flags: posN | posV | posZ | posC, Illegal: true},
+		0xF0: {Name: "BEQ", Mode: relative, Size: 2, Cycles: 2, Do: cpu.beq},
+		0xF1: {Name: "SBC", Mode: indirectY, Size: 2, Cycles: 5, Do: cpu.sbc, PageCross: true, Flags: posN | posV | posZ | posC},
+		0xF2: {Name: "JAM", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.jam, Illegal: true},
+		0xF3: {Name: "ISC", Mode: indirectY, Size: 2, Cycles: 8, Do: cpu.isc, Flags: posN | posV | posZ | posC, Illegal: true},
+		0xF4: {Name: "NOP", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.nop, Illegal: true},
+		0xF5: {Name: "SBC", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.sbc, Flags: posN | posV | posZ | posC},
+		0xF6: {Name: "INC", Mode: zeroPageX, Size: 2, Cycles: 6, Do: cpu.inc, Flags: posN | posZ},
+		0xF7: {Name: "ISC", Mode: zeroPageX, Size: 2, Cycles: 6, Do: cpu.isc, Flags: posN | posV | posZ | posC, Illegal: true},
+		0xF8: {Name: "SED", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.sed, Flags: posD},
+		0xF9: {Name: "SBC", Mode: absoluteY, Size: 3, Cycles: 4, Do: cpu.sbc, PageCross: true, Flags: posN | posV | posZ | posC},
+		0xFA: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.nop, Illegal: true},
+		0xFB: {Name: "ISC", Mode: absoluteY, Size: 3, Cycles: 7, Do: cpu.isc, Flags: posN | posV | posZ | posC, Illegal: true},
+		0xFC: {Name: "NOP", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.nop, PageCross: true, Illegal: true},
+		0xFD: {Name: "SBC", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.sbc, PageCross: true, Flags: posN | posV | posZ | posC},
+		0xFE: {Name: "INC", Mode: absoluteX, Size: 3, Cycles: 7, Do: cpu.inc, Flags: posN | posZ},
+		0xFF: {Name: "ISC", Mode: absoluteX, Size: 3, Cycles: 7, Do: cpu.isc, Flags: posN | posV | posZ | posC, Illegal: true},
+	}
+}
+
+// initializeCMOSOpcodes sets the opcode table of the 65c02 variant.
+func (cpu *CPU) initializeCMOSOpcodes() {
+	cpu.opcodes = [256]opcode{
+		0x00: {Name: "BRK", Mode: implicit, Size: 2, Cycles: 7, Do: cpu.brk, Flags: posD | posI},
+		0x01: {Name: "ORA", Mode: indirectX, Size: 2, Cycles: 6, Do: cpu.ora, Flags: posN | posZ},
+		0x02: {Name: "NOP", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.nop, Illegal: true},
+		0x03: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0x04: {Name: "TSB", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.tsb, Flags: posZ},
+		0x05: {Name: "ORA", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.ora, Flags: posN | posZ},
+		0x06: {Name: "ASL", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.asl, Flags: posN | posZ | posC},
+		0x07: {Name: "RMB0", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.rmb(0)},
+		0x08: {Name: "PHP", Mode: implicit, Size: 1, Cycles: 3, Do: cpu.php},
+		0x09: {Name: "ORA", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.ora, Flags: posN | posZ},
+		0x0A: {Name: "ASL", Mode: accumulator, Size: 1, Cycles: 2, Do: cpu.asl, Flags: posN | posZ | posC},
+		0x0B: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0x0C: {Name: "TSB", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.tsb, Flags: posZ},
+		0x0D: {Name: "ORA", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.ora, Flags: posN | posZ},
+		0x0E: {Name: "ASL", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.asl, Flags: posN | posZ | posC},
+		0x0F: {Name: "BBR0", Mode: zeroPageRelative, Size: 3, Cycles: 5, Do: cpu.bbr(0)},
+		0x10: {Name: "BPL", Mode: relative, Size: 2, Cycles: 2, Do: cpu.bpl},
+		0x11: {Name: "ORA", Mode: indirectY, Size: 2, Cycles: 5, Do: cpu.ora, PageCross: true, Flags: posN | posZ},
+		0x12: {Name: "ORA", Mode: zeroPageIndirect, Size: 2, Cycles: 5, Do: cpu.ora, Flags: posN | posZ},
+		0x13: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0x14: {Name: "TRB", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.trb, Flags: posZ},
+		0x15: {Name: "ORA", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.ora, Flags: posN | posZ},
+		0x16: {Name: "ASL", Mode: zeroPageX, Size: 2, Cycles: 6, Do: cpu.asl, Flags: posN | posZ | posC},
+		0x17: {Name: "RMB1", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.rmb(1)},
+		0x18: {Name: "CLC", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.clc, Flags: posC},
+		0x19: {Name: "ORA", Mode: absoluteY, Size: 3, Cycles: 4, Do: cpu.ora, PageCross: true, Flags: posN | posZ},
+		0x1A: {Name: "INC", Mode: accumulator, Size: 1, Cycles: 2, Do: cpu.inc, Flags: posN | posZ},
+		0x1B: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0x1C: {Name: "TRB", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.trb, Flags: posZ},
+		0x1D: {Name: "ORA", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.ora, PageCross: true, Flags: posN | posZ},
+		0x1E: {Name: "ASL", Mode: absoluteX, Size: 3, Cycles: 6, Do: cpu.asl, PageCross: true, Flags: posN | posZ | posC},
+		0x1F: {Name: "BBR1", Mode: zeroPageRelative, Size: 3, Cycles: 5, Do: cpu.bbr(1)},
+		0x20: {Name: "JSR", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.jsr},
+		0x21: {Name: "AND", Mode: indirectX, Size: 2, Cycles: 6, Do: cpu.and, Flags: posN | posZ},
+		0x22: {Name: "NOP", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.nop, Illegal: true},
+		0x23: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0x24: {Name: "BIT", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.bit, Flags: posN | posV | posZ},
+		0x25: {Name: "AND", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.and, Flags: posN | posZ},
+		0x26: {Name: "ROL", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.rol, Flags: posN | posZ | posC},
+		0x27: {Name: "RMB2", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.rmb(2)},
+		0x28: {Name: "PLP", Mode: implicit, Size: 1, Cycles: 4, Do: cpu.plp, Flags: posN | posV | posD | posI | posZ | posC},
+		0x29: {Name: "AND", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.and, Flags: posN | posZ},
+		0x2A: {Name: "ROL", Mode: accumulator, Size: 1, Cycles: 2, Do: cpu.rol, Flags: posN | posZ | posC},
+		0x2B: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0x2C: {Name: "BIT", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.bit, Flags: posN | posV | posZ},
+		0x2D: {Name: "AND", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.and, Flags: posN | posZ},
+		0x2E: {Name: "ROL", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.rol, Flags: posN | posZ | posC},
+		0x2F: {Name: "BBR2", Mode: zeroPageRelative, Size: 3, Cycles: 5, Do: cpu.bbr(2)},
+		0x30: {Name: "BMI", Mode: relative, Size: 2, Cycles: 2, Do: cpu.bmi},
+		0x31: {Name: "AND", Mode: indirectY, Size: 2, Cycles: 5, Do: cpu.and, PageCross: true, Flags: posN | posZ},
+		0x32: {Name: "AND", Mode: zeroPageIndirect, Size: 2, Cycles: 5, Do: cpu.and, Flags: posN | posZ},
+		0x33: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0x34: {Name: "BIT", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.bit, Flags: posN | posV | posZ},
+		0x35: {Name: "AND", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.and, Flags: posN | posZ},
+		0x36: {Name: "ROL", Mode: zeroPageX, Size: 2, Cycles: 6, Do: cpu.rol, Flags: posN | posZ | posC},
+		0x37: {Name: "RMB3", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.rmb(3)},
+		0x38: {Name: "SEC", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.sec, Flags: posC},
+		0x39: {Name: "AND", Mode: absoluteY, Size: 3, Cycles: 4, Do: cpu.and, PageCross: true, Flags: posN | posZ},
+		0x3A: {Name: "DEC", Mode: accumulator, Size: 1, Cycles: 2, Do: cpu.dec, Flags: posN | posZ},
+		0x3B: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0x3C: {Name: "BIT", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.bit, PageCross: true, Flags: posN | posV | posZ},
+		0x3D: {Name: "AND", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.and, PageCross: true, Flags: posN | posZ},
+		0x3E: {Name: "ROL", Mode: absoluteX, Size: 3, Cycles: 6, Do: cpu.rol, PageCross: true, Flags: posN | posZ | posC},
+		0x3F: {Name: "BBR3", Mode: zeroPageRelative, Size: 3, Cycles: 5, Do: cpu.bbr(3)},
+		0x40: {Name: "RTI", Mode: implicit, Size: 1, Cycles: 6, Do: cpu.rti, Flags: posN | posV | posD | posI | posZ | posC},
+		0x41: {Name: "EOR", Mode: indirectX, Size: 2, Cycles: 6, Do: cpu.eor, Flags: posN | posZ},
+		0x42: {Name: "NOP", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.nop, Illegal: true},
+		0x43: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0x44: {Name: "NOP", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.nop, Illegal: true},
+		0x45: {Name: "EOR", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.eor, Flags: posN | posZ},
+		0x46: {Name: "LSR", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.lsr, Flags: posN | posZ | posC},
+		0x47: {Name: "RMB4", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.rmb(4)},
+		0x48: {Name: "PHA", Mode: implicit, Size: 1, Cycles: 3, Do: cpu.pha},
+		0x49: {Name: "EOR", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.eor, Flags: posN | posZ},
+		0x4A: {Name: "LSR", Mode: accumulator, Size: 1, Cycles: 2, Do: cpu.lsr, Flags: posN | posZ | posC},
+		0x4B: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0x4C: {Name: "JMP", Mode: absolute, Size: 3, Cycles: 3, Do: cpu.jmp},
+		0x4D: {Name: "EOR", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.eor, Flags: posN | posZ},
+		0x4E: {Name: "LSR", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.lsr, Flags: posN | posZ | posC},
+		0x4F: {Name: "BBR4", Mode: zeroPageRelative, Size: 3, Cycles: 5, Do: cpu.bbr(4)},
+		0x50: {Name: "BVC", Mode: relative, Size: 2, Cycles: 2, Do: cpu.bvc},
+		0x51: {Name: "EOR", Mode: indirectY, Size: 2, Cycles: 5, Do: cpu.eor, PageCross: true, Flags: posN | posZ},
+		0x52: {Name: "EOR", Mode: zeroPageIndirect, Size: 2, Cycles: 5, Do: cpu.eor, Flags: posN | posZ},
+		0x53: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0x54: {Name: "NOP", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.nop, Illegal: true},
+		0x55: {Name: "EOR", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.eor, Flags: posN | posZ},
+		0x56: {Name: "LSR", Mode: zeroPageX, Size: 2, Cycles: 6, Do: cpu.lsr, Flags: posN | posZ | posC},
+		0x57: {Name: "RMB5", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.rmb(5)},
+		0x58: {Name: "CLI", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.cli, Flags: posI},
+		0x59: {Name: "EOR", Mode: absoluteY, Size: 3, Cycles: 4, Do: cpu.eor, PageCross: true, Flags: posN | posZ},
+		0x5A: {Name: "PHY", Mode: implicit, Size: 1, Cycles: 3, Do: cpu.phy},
+		0x5B: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0x5C: {Name: "NOP", Mode: absolute, Size: 3, Cycles: 8, Do: cpu.nop, Illegal: true},
+		0x5D: {Name: "EOR", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.eor, PageCross: true, Flags: posN | posZ},
+		0x5E: {Name: "LSR", Mode: absoluteX, Size: 3, Cycles: 6, Do: cpu.lsr, PageCross: true, Flags: posN | posZ | posC},
+		0x5F: {Name: "BBR5", Mode: zeroPageRelative, Size: 3, Cycles: 5, Do: cpu.bbr(5)},
+		0x60: {Name: "RTS", Mode: implicit, Size: 1, Cycles: 6, Do: cpu.rts},
+		0x61: {Name: "ADC", Mode: indirectX, Size: 2, Cycles: 6, Do: cpu.adc, Flags: posN | posV | posZ | posC},
+		0x62: {Name: "NOP", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.nop, Illegal: true},
+		0x63: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0x64: {Name: "STZ", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.stz},
+		0x65: {Name: "ADC", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.adc, Flags: posN | posV | posZ | posC},
+		0x66: {Name: "ROR", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.ror, Flags: posN | posZ | posC},
+		0x67: {Name: "RMB6", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.rmb(6)},
+		0x68: {Name: "PLA", Mode: implicit, Size: 1, Cycles: 4, Do: cpu.pla, Flags: posN | posZ},
+		0x69: {Name: "ADC", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.adc, Flags: posN | posV | posZ | posC},
+		0x6A: {Name: "ROR", Mode: accumulator, Size: 1, Cycles: 2, Do: cpu.ror, Flags: posN | posZ | posC},
+		0x6B: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0x6C: {Name: "JMP", Mode: indirect, Size: 3, Cycles: 6, Do: cpu.jmp},
+		0x6D: {Name: "ADC", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.adc, Flags: posN | posV | posZ | posC},
+		0x6E: {Name: "ROR", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.ror, Flags: posN | posZ | posC},
+		0x6F: {Name: "BBR6", Mode: zeroPageRelative, Size: 3, Cycles: 5, Do: cpu.bbr(6)},
+		0x70: {Name: "BVS", Mode: relative, Size: 2, Cycles: 2, Do: cpu.bvs},
+		0x71: {Name: "ADC", Mode: indirectY, Size: 2, Cycles: 5, Do: cpu.adc, PageCross: true, Flags: posN | posV | posZ | posC},
+		0x72: {Name: "ADC", Mode: zeroPageIndirect, Size: 2, Cycles: 5, Do: cpu.adc, Flags: posN | posV | posZ | posC},
+		0x73: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0x74: {Name: "STZ", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.stz},
+		0x75: {Name: "ADC", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.adc, Flags: posN | posV | posZ | posC},
+		0x76: {Name: "ROR", Mode: zeroPageX, Size: 2, Cycles: 6, Do: cpu.ror, Flags: posN | posZ | posC},
+		0x77: {Name: "RMB7", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.rmb(7)},
+		0x78: {Name: "SEI", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.sei, Flags: posI},
+		0x79: {Name: "ADC", Mode: absoluteY, Size: 3, Cycles: 4, Do: cpu.adc, PageCross: true, Flags: posN | posV | posZ | posC},
+		0x7A: {Name: "PLY", Mode: implicit, Size: 1, Cycles: 4, Do: cpu.ply, Flags: posN | posZ},
+		0x7B: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0x7C: {Name: "JMP", Mode: absoluteIndirectX, Size: 3, Cycles: 6, Do: cpu.jmp},
+		0x7D: {Name: "ADC", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.adc, PageCross: true, Flags: posN | posV | posZ | posC},
+		0x7E: {Name: "ROR", Mode: absoluteX, Size: 3, Cycles: 6, Do: cpu.ror, PageCross: true, Flags: posN | posZ | posC},
+		0x7F: {Name: "BBR7", Mode: zeroPageRelative, Size: 3, Cycles: 5, Do: cpu.bbr(7)},
+		0x80: {Name: "BRA", Mode: relative, Size: 2, Cycles: 2, Do: cpu.bra},
+		0x81: {Name: "STA", Mode: indirectX, Size: 2, Cycles: 6, Do: cpu.sta},
+		0x82: {Name: "NOP", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.nop, Illegal: true},
+		0x83: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0x84: {Name: "STY", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.sty},
+		0x85: {Name: "STA", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.sta},
+		0x86: {Name: "STX", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.stx},
+		0x87: {Name: "SMB0", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.smb(0)},
+		0x88: {Name: "DEY", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.dey, Flags: posN | posZ},
+		0x89: {Name: "BIT", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.bit, Flags: posZ},
+		0x8A: {Name: "TXA", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.txa, Flags: posN | posZ},
+		0x8B: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0x8C: {Name: "STY", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.sty},
+		0x8D: {Name: "STA", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.sta},
+		0x8E: {Name: "STX", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.stx},
+		0x8F: {Name: "BBS0", Mode: zeroPageRelative, Size: 3, Cycles: 5, Do: cpu.bbs(0)},
+		0x90: {Name: "BCC", Mode: relative, Size: 2, Cycles: 2, Do: cpu.bcc},
+		0x91: {Name: "STA", Mode: indirectY, Size: 2, Cycles: 6, Do: cpu.sta},
+		0x92: {Name: "STA", Mode: zeroPageIndirect, Size: 2, Cycles: 5, Do: cpu.sta},
+		0x93: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0x94: {Name: "STY", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.sty},
+		0x95: {Name: "STA", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.sta},
+		0x96: {Name: "STX", Mode: zeroPageY, Size: 2, Cycles: 4, Do: cpu.stx},
+		0x97: {Name: "SMB1", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.smb(1)},
+		0x98: {Name: "TYA", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.tya, Flags: posN | posZ},
+		0x99: {Name: "STA", Mode: absoluteY, Size: 3, Cycles: 5, Do: cpu.sta},
+		0x9A: {Name: "TXS", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.txs},
+		0x9B: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0x9C: {Name: "STZ", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.stz},
+		0x9D: {Name: "STA", Mode: absoluteX, Size: 3, Cycles: 5, Do: cpu.sta},
+		0x9E: {Name: "STZ", Mode: absoluteX, Size: 3, Cycles: 5, Do: cpu.stz},
+		0x9F: {Name: "BBS1", Mode: zeroPageRelative, Size: 3, Cycles: 5, Do: cpu.bbs(1)},
+		0xA0: {Name: "LDY", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.ldy, Flags: posN | posZ},
+		0xA1: {Name: "LDA", Mode: indirectX, Size: 2, Cycles: 6, Do: cpu.lda, Flags: posN | posZ},
+		0xA2: {Name: "LDX", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.ldx, Flags: posN | posZ},
+		0xA3: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0xA4: {Name: "LDY", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.ldy, Flags: posN | posZ},
+		0xA5: {Name: "LDA", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.lda, Flags: posN | posZ},
+		0xA6: {Name: "LDX", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.ldx, Flags: posN | posZ},
+		0xA7: {Name: "SMB2", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.smb(2)},
+		0xA8: {Name: "TAY", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.tay, Flags: posN | posZ},
+		0xA9: {Name: "LDA", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.lda, Flags: posN | posZ},
+		0xAA: {Name: "TAX", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.tax, Flags: posN | posZ},
+		0xAB: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0xAC: {Name: "LDY", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.ldy, Flags: posN | posZ},
+		0xAD: {Name: "LDA", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.lda, Flags: posN | posZ},
+		0xAE: {Name: "LDX", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.ldx, Flags: posN | posZ},
+		0xAF: {Name: "BBS2", Mode: zeroPageRelative, Size: 3, Cycles: 5, Do: cpu.bbs(2)},
+		0xB0: {Name: "BCS", Mode: relative, Size: 2, Cycles: 2, Do: cpu.bcs},
+		0xB1: {Name: "LDA", Mode: indirectY, Size: 2, Cycles: 5, Do: cpu.lda, PageCross: true, Flags: posN | posZ},
+		0xB2: {Name: "LDA", Mode: zeroPageIndirect, Size: 2, Cycles: 5, Do: cpu.lda, Flags: posN | posZ},
+		0xB3: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0xB4: {Name: "LDY", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.ldy, Flags: posN | posZ},
+		0xB5: {Name: "LDA", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.lda, Flags: posN | posZ},
+		0xB6: {Name: "LDX", Mode: zeroPageY, Size: 2, Cycles: 4, Do: cpu.ldx, Flags: posN | posZ},
+		0xB7: {Name: "SMB3", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.smb(3)},
+		0xB8: {Name: "CLV", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.clv, Flags: posV},
+		0xB9: {Name: "LDA", Mode: absoluteY, Size: 3, Cycles: 4, Do: cpu.lda, PageCross: true, Flags: posN | posZ},
+		0xBA: {Name: "TSX", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.tsx, Flags: posN | posZ},
+		0xBB: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0xBC: {Name: "LDY", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.ldy, PageCross: true, Flags: posN | posZ},
+		0xBD: {Name: "LDA", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.lda, PageCross: true, Flags: posN | posZ},
+		0xBE: {Name: "LDX", Mode: absoluteY, Size: 3, Cycles: 4, Do: cpu.ldx, PageCross: true, Flags: posN | posZ},
+		0xBF: {Name: "BBS3", Mode: zeroPageRelative, Size: 3, Cycles: 5, Do: cpu.bbs(3)},
+		0xC0: {Name: "CPY", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.cpy, Flags: posN | posZ | posC},
+		0xC1: {Name: "CMP", Mode: indirectX, Size: 2, Cycles: 6, Do: cpu.cmp, Flags: posN | posZ | posC},
+		0xC2: {Name: "NOP", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.nop, Illegal: true},
+		0xC3: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0xC4: {Name: "CPY", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.cpy, Flags: posN | posZ | posC},
+		0xC5: {Name: "CMP", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.cmp, Flags: posN | posZ | posC},
+		0xC6: {Name: "DEC", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.dec, Flags: posN | posZ},
+		0xC7: {Name: "SMB4", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.smb(4)},
+		0xC8: {Name: "INY", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.iny, Flags: posN | posZ},
+		0xC9: {Name: "CMP", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.cmp, Flags: posN | posZ | posC},
+		0xCA: {Name: "DEX", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.dex, Flags: posN | posZ},
+		0xCB: {Name: "WAI", Mode: implicit, Size: 1, Cycles: 3, Do: cpu.wai},
+		0xCC: {Name: "CPY", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.cpy, Flags: posN | posZ | posC},
+		0xCD: {Name: "CMP", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.cmp, Flags: posN | posZ | posC},
+		0xCE: {Name: "DEC", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.dec, Flags: posN | posZ},
+		0xCF: {Name: "BBS4", Mode: zeroPageRelative, Size: 3, Cycles: 5, Do: cpu.bbs(4)},
+		0xD0: {Name: "BNE", Mode: relative, Size: 2, Cycles: 2, Do: cpu.bne},
+		0xD1: {Name: "CMP", Mode: indirectY, Size: 2, Cycles: 5, Do: cpu.cmp, PageCross: true, Flags: posN | posZ | posC},
+		0xD2: {Name: "CMP", Mode: zeroPageIndirect, Size: 2, Cycles: 5, Do: cpu.cmp, Flags: posN | posZ | posC},
+		0xD3: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0xD4: {Name: "NOP", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.nop, Illegal: true},
+		0xD5: {Name: "CMP", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.cmp, Flags: posN | posZ | posC},
+		0xD6: {Name: "DEC", Mode: zeroPageX, Size: 2, Cycles: 6, Do: cpu.dec, Flags: posN | posZ},
+		0xD7: {Name: "SMB5", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.smb(5)},
+		0xD8: {Name: "CLD", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.cld, Flags: posD},
+		0xD9: {Name: "CMP", Mode: absoluteY, Size: 3, Cycles: 4, Do: cpu.cmp, PageCross: true, Flags: posN | posZ | posC},
+		0xDA: {Name: "PHX", Mode: implicit, Size: 1, Cycles: 3, Do: cpu.phx},
+		0xDB: {Name: "STP", Mode: implicit, Size: 1, Cycles: 3, Do: cpu.stp},
+		0xDC: {Name: "NOP", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.nop, Illegal: true},
+		0xDD: {Name: "CMP", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.cmp, PageCross: true, Flags: posN | posZ | posC},
+		0xDE: {Name: "DEC", Mode: absoluteX, Size: 3, Cycles: 7, Do: cpu.dec, Flags: posN | posZ},
+		0xDF: {Name: "BBS5", Mode: zeroPageRelative, Size: 3, Cycles: 5, Do: cpu.bbs(5)},
+		0xE0: {Name: "CPX", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.cpx, Flags: posN | posZ | posC},
+		0xE1: {Name: "SBC", Mode: indirectX, Size: 2, Cycles: 6, Do: cpu.sbc, Flags: posN | posV | posZ | posC},
+		0xE2: {Name: "NOP", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.nop, Illegal: true},
+		0xE3: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0xE4: {Name: "CPX", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.cpx, Flags: posN | posZ | posC},
+		0xE5: {Name: "SBC", Mode: zeroPage, Size: 2, Cycles: 3, Do: cpu.sbc, Flags: posN | posV | posZ | posC},
+		0xE6: {Name: "INC", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.inc, Flags: posN | posZ},
+		0xE7: {Name: "SMB6", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.smb(6)},
+		0xE8: {Name: "INX", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.inx, Flags: posN | posZ},
+		0xE9: {Name: "SBC", Mode: immediate, Size: 2, Cycles: 2, Do: cpu.sbc, Flags: posN | posV | posZ | posC},
+		0xEA: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.nop},
+		0xEB: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0xEC: {Name: "CPX", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.cpx, Flags: posN | posZ | posC},
+		0xED: {Name: "SBC", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.sbc, Flags: posN | posV | posZ | posC},
+		0xEE: {Name: "INC", Mode: absolute, Size: 3, Cycles: 6, Do: cpu.inc, Flags: posN | posZ},
+		0xEF: {Name: "BBS6", Mode: zeroPageRelative, Size: 3, Cycles: 5, Do: cpu.bbs(6)},
+		0xF0: {Name: "BEQ", Mode: relative, Size: 2, Cycles: 2, Do: cpu.beq},
+		0xF1: {Name: "SBC", Mode: indirectY, Size: 2, Cycles: 5, Do: cpu.sbc, PageCross: true, Flags: posN | posV | posZ | posC},
+		0xF2: {Name: "SBC", Mode: zeroPageIndirect, Size: 2, Cycles: 5, Do: cpu.sbc, Flags: posN | posV | posZ | posC},
+		0xF3: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0xF4: {Name: "NOP", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.nop, Illegal: true},
+		0xF5: {Name: "SBC", Mode: zeroPageX, Size: 2, Cycles: 4, Do: cpu.sbc, Flags: posN | posV | posZ | posC},
+		0xF6: {Name: "INC", Mode: zeroPageX, Size: 2, Cycles: 6, Do: cpu.inc, Flags: posN | posZ},
+		0xF7: {Name: "SMB7", Mode: zeroPage, Size: 2, Cycles: 5, Do: cpu.smb(7)},
+		0xF8: {Name: "SED", Mode: implicit, Size: 1, Cycles: 2, Do: cpu.sed, Flags: posD},
+		0xF9: {Name: "SBC", Mode: absoluteY, Size: 3, Cycles: 4, Do: cpu.sbc, PageCross: true, Flags: posN | posV | posZ | posC},
+		0xFA: {Name: "PLX", Mode: implicit, Size: 1, Cycles: 4, Do: cpu.plx, Flags: posN | posZ},
+		0xFB: {Name: "NOP", Mode: implicit, Size: 1, Cycles: 1, Do: cpu.nop, Illegal: true},
+		0xFC: {Name: "NOP", Mode: absolute, Size: 3, Cycles: 4, Do: cpu.nop, Illegal: true},
+		0xFD: {Name: "SBC", Mode: absoluteX, Size: 3, Cycles: 4, Do: cpu.sbc, PageCross: true, Flags: posN | posV | posZ | posC},
+		0xFE: {Name: "INC", Mode: absoluteX, Size: 3, Cycles: 7, Do: cpu.inc, Flags: posN | posZ},
+		0xFF: {Name: "BBS7", Mode: zeroPageRelative, Size: 3, Cycles: 5, Do: cpu.bbs(7)},
+	}
+}
@@ -0,0 +1,107 @@
+package cpu_test
+
+import (
+	"context"
+	"testing"
+
+	"nes/pkg/asm"
+	"nes/pkg/cpu"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// run assembles src, loads it and runs it until it gets to the done label.
+// It's an external test so it can use the assembler, which imports cpu.
+func run(src string, opts ...cpu.Option) *cpu.CPU {
+	prog := asm.MustAssemble(src)
+	c := cpu.New(opts...)
+	So(c.Load(&prog.Program), ShouldBeNil)
+	c.SetBreakpoint(prog.Symbols["done"])
+	So(c.Run(context.Background(), 1_000_000).Reason, ShouldEqual, cpu.StopBreakpoint)
+	return c
+}
+
+func TestPrograms(t *testing.T) {
+	Convey("runs programs", t, func() {
+		Convey("multiply by shifting and adding", func() {
+			c := run(`
+A_ = $10
+B_ = $11
+PRODUCT = $12 ; 16 bits
+	.org $0200
+multiply:
+	LDA #13
+	STA A_
+	LDA #21
+	STA B_
+	LDA #0
+	STA PRODUCT+1
+	LDX #8
+@loop:
+	LSR B_
+	BCC @skip
+	CLC
+	LDA PRODUCT+1
+	ADC A_
+	STA PRODUCT+1
+@skip:
+	ROR PRODUCT+1
+	ROR PRODUCT
+	DEX
+	BNE @loop
+done:
+	JMP done`)
+			So(int(c.Peek(0x13))<<8|int(c.Peek(0x12)), ShouldEqual, 13*21)
+		})
+
+		Convey("copy a string through a pointer and call a subroutine", func() {
+			c := run(`
+SRC = $20
+DST = $22
+	.org $0400
+	LDA #<msg
+	STA SRC
+	LDA #>msg
+	STA SRC+1
+	LDA #<$0300
+	STA DST
+	LDA #>$0300
+	STA DST+1
+	JSR copy
+done:
+	JMP done
+
+copy:
+	LDY #0
+@loop:
+	LDA (SRC),Y
+	STA (DST),Y
+	BEQ @end
+	INY
+	BNE @loop
+@end:
+	RTS
+
+msg: .byte "hello", 0`)
+			got := []byte{}
+			for addr := uint16(0x0300); c.Peek(addr) != 0; addr++ {
+				got = append(got, c.Peek(addr))
+			}
+			So(string(got), ShouldEqual, "hello")
+			So(c.Registers().Y, ShouldEqual, 5)
+		})
+
+		Convey("decimal mode on the NMOS 6502 but not the 2A03", func() {
+			src := `
+	.org $0200
+	SED
+	CLC
+	LDA #$19
+	ADC #$28
+done:
+	JMP done`
+			So(run(src, cpu.WithVariant(cpu.VariantNMOS)).Registers().A, ShouldEqual, 0x47)
+			So(run(src).Registers().A, ShouldEqual, 0x41)
+		})
+	})
+}
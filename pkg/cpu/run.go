@@ -0,0 +1,137 @@
+package cpu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrHalted is returned by [CPU.Step] when a JAM opcode has halted the CPU. Only a reset will get it going again.
+var ErrHalted = errors.New("cpu is halted")
+
+// StepInfo describes what a single [CPU.Step] ran.
+type StepInfo struct {
+	PC     uint16 // address of the instruction, or the pc that was interrupted
+	Opcode byte   // the opcode at PC
+	Name   string // mnemonic of the instruction, or "NMI" or "IRQ" for an interrupt sequence
+	// Addr is the effective address the instruction operated on, or the vector an interrupt sequence loaded the pc from.
+	// It's 0 for modes without an address.
+	Addr      uint16
+	Cycles    int  // cycles it took
+	Interrupt bool // an interrupt sequence ran instead of an instruction
+}
+
+func (i StepInfo) String() string {
+	return fmt.Sprintf("0x%04x: %v (opcode 0x%02x) addr 0x%04x in %v cycles", i.PC, i.Name, i.Opcode, i.Addr, i.Cycles)
+}
+
+// Step executes one instruction, or the interrupt sequence if an interrupt is due, and returns what it ran.
+// It uses the bus-cycle-accurate executor if the CPU is in accurate mode.
+//
+// A halted CPU doesn't do anything and returns [ErrHalted].
+// In strict mode an illegal opcode isn't run and the error is an [*IllegalOpcodeError].
+func (cpu *CPU) Step() (StepInfo, error) {
+	if cpu.halted {
+		return StepInfo{PC: cpu.pc, Opcode: cpu.peek(cpu.pc), Name: cpu.opcodes[cpu.peek(cpu.pc)].Name}, ErrHalted
+	}
+	if cpu.accurate {
+		return cpu.stepAccurate()
+	}
+	return cpu.step()
+}
+
+// SetBreakpoint makes [CPU.Run] stop before executing the instruction at addr.
+func (cpu *CPU) SetBreakpoint(addr uint16) {
+	if cpu.breakpoints == nil {
+		cpu.breakpoints = map[uint16]bool{}
+	}
+	cpu.breakpoints[addr] = true
+}
+
+// ClearBreakpoint removes the breakpoint at addr, if there is one.
+func (cpu *CPU) ClearBreakpoint(addr uint16) {
+	delete(cpu.breakpoints, addr)
+}
+
+// StopReason is why [CPU.Run] returned.
+type StopReason int
+
+const (
+	StopBudget     StopReason = iota // the cycle budget ran out
+	StopBreakpoint                   // the pc reached a breakpoint
+	StopJam                          // a JAM opcode halted the CPU, only a reset will get it going again
+	StopIllegal                      // an illegal opcode was fetched in strict mode
+	StopCanceled                     // the context was canceled
+)
+
+func (r StopReason) String() string {
+	switch r {
+	case StopBudget:
+		return "budget"
+	case StopBreakpoint:
+		return "breakpoint"
+	case StopJam:
+		return "jam"
+	case StopIllegal:
+		return "illegal"
+	case StopCanceled:
+		return "canceled"
+	default:
+		return fmt.Sprintf("StopReason(%d)", int(r))
+	}
+}
+
+// Stop is returned by [CPU.Run] to say why and where the CPU stopped.
+type Stop struct {
+	Reason StopReason
+	PC     uint16 // address of the instruction that stopped the CPU, or the next one to run
+	Opcode byte   // the opcode at PC
+	Err    error  // the [*IllegalOpcodeError] for [StopIllegal] or the context's error for [StopCanceled]
+}
+
+func (s Stop) String() string {
+	if s.Err != nil {
+		return fmt.Sprintf("%v at 0x%04x (opcode 0x%02x): %v", s.Reason, s.PC, s.Opcode, s.Err)
+	}
+	return fmt.Sprintf("%v at 0x%04x (opcode 0x%02x)", s.Reason, s.PC, s.Opcode)
+}
+
+// ctxCheckInterval is how many instructions [CPU.Run] executes between checks of its context.
+const ctxCheckInterval = 1024
+
+// Run executes instructions with [CPU.Step] until something stops it and returns why.
+//
+// It stops once it's used up budget cycles (0 means no budget), before the instruction at a breakpoint,
+// when a JAM halts the CPU, on an illegal opcode in strict mode, or when ctx is canceled.
+// The breakpoint at the pc Run starts from is ignored so it can be called again to continue past it.
+func (cpu *CPU) Run(ctx context.Context, budget uint64) Stop {
+	done := ctx.Done()
+	start := cpu.cycles
+	for n := 0; ; n++ {
+		stop := Stop{PC: cpu.pc, Opcode: cpu.peek(cpu.pc)}
+		if n%ctxCheckInterval == 0 {
+			select {
+			case <-done:
+				stop.Reason, stop.Err = StopCanceled, ctx.Err()
+				return stop
+			default:
+			}
+		}
+		switch {
+		case cpu.halted:
+			stop.Reason = StopJam
+			return stop
+		case n > 0 && cpu.breakpoints[cpu.pc]:
+			stop.Reason = StopBreakpoint
+			return stop
+		case budget > 0 && cpu.cycles-start >= budget:
+			stop.Reason = StopBudget
+			return stop
+		}
+
+		if _, err := cpu.Step(); err != nil {
+			stop.Reason, stop.Err = StopIllegal, err
+			return stop
+		}
+	}
+}
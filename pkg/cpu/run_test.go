@@ -0,0 +1,96 @@
+package cpu
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRun(t *testing.T) {
+	Convey("should step and run", t, func() {
+		cpu := newCPU()
+		cpu.s = 0xfd
+		cpu.write16(irqVector, 0x1234)
+
+		Convey("step returns what it ran", func() {
+			copy(cpu.ram()[:], []byte{0xbd, 0xf0, 0x03}) // LDA $03F0,X
+			cpu.x = 0x20
+			info, err := cpu.Step()
+			So(err, ShouldBeNil)
+			So(info, ShouldResemble, StepInfo{PC: 0x0000, Opcode: 0xbd, Name: "LDA", Addr: 0x0410, Cycles: 5})
+			So(info.String(), ShouldEqual, "0x0000: LDA (opcode 0xbd) addr 0x0410 in 5 cycles")
+		})
+
+		Convey("step returns interrupt sequences", func() {
+			cpu.write16(nmiVector, 0x5678)
+			cpu.SetNMI(true)
+			info, err := cpu.Step()
+			So(err, ShouldBeNil)
+			So(info, ShouldResemble, StepInfo{PC: 0x0000, Opcode: 0x00, Name: "NMI", Addr: nmiVector, Cycles: 7, Interrupt: true})
+			So(cpu.pc, ShouldEqual, 0x5678)
+		})
+
+		Convey("step does nothing when halted", func() {
+			copy(cpu.ram()[:], []byte{0x02}) // JAM
+			_, err := cpu.Step()
+			So(err, ShouldBeNil)
+			info, err := cpu.Step()
+			So(err, ShouldEqual, ErrHalted)
+			So(info, ShouldResemble, StepInfo{PC: 0x0000, Opcode: 0x02, Name: "JAM"})
+		})
+
+		Convey("brk is a normal interrupt", func() {
+			copy(cpu.ram()[:], []byte{0x00, 0x00}) // BRK
+			copy(cpu.ram()[0x1234:], []byte{0xe8}) // INX in the handler
+			stop := cpu.Run(context.Background(), 8)
+			So(stop, ShouldResemble, Stop{Reason: StopBudget, PC: 0x1235, Opcode: 0x00})
+			So(cpu.x, ShouldEqual, 1)
+			So(cpu.cycles, ShouldEqual, 9)
+		})
+
+		Convey("stops at a breakpoint and continues past it", func() {
+			copy(cpu.ram()[:], []byte{0xe8, 0xe8, 0x4c, 0x00, 0x00}) // loop: INX, INX, JMP loop
+			cpu.SetBreakpoint(0x0001)
+			stop := cpu.Run(context.Background(), 0)
+			So(stop, ShouldResemble, Stop{Reason: StopBreakpoint, PC: 0x0001, Opcode: 0xe8})
+			So(cpu.x, ShouldEqual, 1)
+
+			stop = cpu.Run(context.Background(), 0)
+			So(stop.Reason, ShouldEqual, StopBreakpoint)
+			So(cpu.x, ShouldEqual, 3)
+
+			cpu.ClearBreakpoint(0x0001)
+			stop = cpu.Run(context.Background(), 100)
+			So(stop.Reason, ShouldEqual, StopBudget)
+		})
+
+		Convey("stops once the budget is used up", func() {
+			copy(cpu.ram()[:], []byte{0x4c, 0x00, 0x00}) // JMP to itself
+			stop := cpu.Run(context.Background(), 10)
+			So(stop.Reason, ShouldEqual, StopBudget)
+			So(cpu.cycles, ShouldEqual, 12) // the last instruction can run over
+		})
+
+		Convey("stops on a jam", func() {
+			copy(cpu.ram()[:], []byte{0xe8, 0x22}) // INX, JAM
+			stop := cpu.Run(context.Background(), 0)
+			So(stop, ShouldResemble, Stop{Reason: StopJam, PC: 0x0001, Opcode: 0x22})
+		})
+
+		Convey("stops when the context is canceled", func() {
+			copy(cpu.ram()[:], []byte{0x4c, 0x00, 0x00})
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			stop := cpu.Run(ctx, 0)
+			So(stop, ShouldResemble, Stop{Reason: StopCanceled, PC: 0x0000, Opcode: 0x4c, Err: context.Canceled})
+			So(cpu.cycles, ShouldEqual, 0)
+		})
+
+		Convey("stop reasons print", func() {
+			So(Stop{Reason: StopJam, PC: 0x8000, Opcode: 0x02}.String(), ShouldEqual, "jam at 0x8000 (opcode 0x02)")
+			So(Stop{Reason: StopCanceled, PC: 0x8000, Opcode: 0x02, Err: context.Canceled}.String(), ShouldEqual, "canceled at 0x8000 (opcode 0x02): context canceled")
+			So(StopReason(9).String(), ShouldEqual, "StopReason(9)")
+		})
+	})
+}
@@ -0,0 +1,178 @@
+package cpu
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// Tom Harte's SingleStepTests run one instruction from a random state and record the state after it
+// and every bus cycle it did, 10,000 of them for each opcode of each chip.
+// The suite is too big for the repo, clone https://github.com/SingleStepTests/65x02 into testdata to run it.
+// https://github.com/SingleStepTests/65x02
+
+// singleStepSuites are the chips in the suite this CPU emulates. Only the NMOS chips' bus cycles are checked since the
+// accurate executor doesn't do the 65C02, its cycles are only counted.
+var singleStepSuites = []struct {
+	dir     string
+	variant Variant
+}{
+	{"nes6502", Variant2A03},
+	{"6502", VariantNMOS},
+	{"wdc65c02", Variant65C02},
+}
+
+// singleStepMagic is the constant the suite uses for ANE and LXA.
+const singleStepMagic = 0xEE
+
+// singleStepState is the CPU and the memory it uses before or after a test.
+type singleStepState struct {
+	PC  uint16      `json:"pc"`
+	S   byte        `json:"s"`
+	A   byte        `json:"a"`
+	X   byte        `json:"x"`
+	Y   byte        `json:"y"`
+	P   byte        `json:"p"`
+	RAM [][2]uint16 `json:"ram"` // address, value
+}
+
+type singleStepTest struct {
+	Name    string          `json:"name"`
+	Initial singleStepState `json:"initial"`
+	Final   singleStepState `json:"final"`
+	Cycles  [][3]any        `json:"cycles"` // address, value, "read" or "write"
+}
+
+// recordingBus is sparse memory that records every access, so each test only has to set the few bytes it uses.
+type recordingBus struct {
+	mem    map[uint16]byte
+	cycles []busCycle
+}
+
+func (b *recordingBus) Read(addr uint16) byte {
+	dat := b.mem[addr]
+	b.cycles = append(b.cycles, r(addr, dat))
+	return dat
+}
+
+func (b *recordingBus) Write(addr uint16, dat byte) {
+	b.mem[addr] = dat
+	b.cycles = append(b.cycles, w(addr, dat))
+}
+
+func (b *recordingBus) Peek(addr uint16) byte {
+	return b.mem[addr]
+}
+
+// runSingleStep runs a test and returns what didn't match.
+// B and bit 5 of P aren't compared since they aren't real flags.
+func runSingleStep(cpu *CPU, bus *recordingBus, test singleStepTest) []string {
+	clear(bus.mem)
+	for _, m := range test.Initial.RAM {
+		bus.mem[m[0]] = byte(m[1])
+	}
+	in := test.Initial
+	cpu.SetRegisters(Registers{PC: in.PC, A: in.A, X: in.X, Y: in.Y, S: in.S, P: Status(in.P)})
+	cpu.halted, cpu.waiting = false, false
+	bus.cycles = nil
+
+	info, err := cpu.Step()
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	mismatches := []string{}
+	want, got := test.Final, cpu.Registers()
+	for _, reg := range []struct {
+		name      string
+		want, got uint16
+	}{
+		{"pc", want.PC, got.PC}, {"s", uint16(want.S), uint16(got.S)}, {"a", uint16(want.A), uint16(got.A)},
+		{"x", uint16(want.X), uint16(got.X)}, {"y", uint16(want.Y), uint16(got.Y)},
+		{"p", uint16(want.P | posB | pos_), uint16(byte(got.P) | posB | pos_)},
+	} {
+		if reg.want != reg.got {
+			mismatches = append(mismatches, fmt.Sprintf("%v is %02x, want %02x", reg.name, reg.got, reg.want))
+		}
+	}
+	for _, m := range want.RAM {
+		if got := bus.mem[m[0]]; got != byte(m[1]) {
+			mismatches = append(mismatches, fmt.Sprintf("$%04x is %02x, want %02x", m[0], got, m[1]))
+		}
+	}
+
+	wantCycles := []busCycle{}
+	for _, c := range test.Cycles {
+		wantCycles = append(wantCycles, busCycle{addr: uint16(c[0].(float64)), dat: byte(c[1].(float64)), write: c[2] == "write"})
+	}
+	if !cpu.accurate {
+		if info.Cycles != len(wantCycles) {
+			mismatches = append(mismatches, fmt.Sprintf("took %v cycles, want %v", info.Cycles, len(wantCycles)))
+		}
+	} else if !slices.Equal(bus.cycles, wantCycles) {
+		mismatches = append(mismatches, fmt.Sprintf("bus cycles are %v, want %v", bus.cycles, wantCycles))
+	}
+	return mismatches
+}
+
+// runSingleStepFile runs every test in a file of the suite and returns a summary of the ones that failed.
+func runSingleStepFile(path string, variant Variant) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tests []singleStepTest
+	if err := json.Unmarshal(data, &tests); err != nil {
+		return nil, fmt.Errorf("%v: %w", path, err)
+	}
+
+	bus := &recordingBus{mem: map[uint16]byte{}}
+	cpu := New(WithBus(bus), WithVariant(variant), WithAccurate(variant != Variant65C02), WithMagic(singleStepMagic))
+	failures := []string{}
+	for _, test := range tests {
+		if mismatches := runSingleStep(cpu, bus, test); len(mismatches) > 0 {
+			failures = append(failures, fmt.Sprintf("%v: %v", test.Name, strings.Join(mismatches, ", ")))
+		}
+	}
+	return failures, nil
+}
+
+func TestSingleStep(t *testing.T) {
+	Convey("the harness checks registers, memory and bus cycles", t, func() {
+		failures, err := runSingleStepFile(filepath.Join("testdata", "singlestep", "sample.json"), Variant2A03)
+		So(err, ShouldBeNil)
+		So(failures, ShouldResemble, []string{
+			"wrong on purpose: a is 5a, want 5b, $0210 is 5a, want 00, " +
+				"bus cycles are [2000 9d read 2001 00 read 2002 02 read 0210 00 read 0210 5a write], " +
+				"want [2000 9d read 2001 00 read 2002 02 read 0210 00 read]",
+		})
+	})
+
+	for _, suite := range singleStepSuites {
+		dir := filepath.Join("testdata", "65x02", suite.dir, "v1")
+		if _, err := os.Stat(dir); err != nil {
+			t.Logf("skipping the %v suite, %v is missing", suite.variant, dir)
+			continue
+		}
+		opcodes := New(WithVariant(suite.variant)).opcodes
+		for opc, op := range opcodes {
+			if op.Name == "JAM" || op.Name == "STP" || op.Name == "WAI" {
+				continue // they stop the CPU, which the suite records as cycles that go on forever
+			}
+			Convey(fmt.Sprintf("%v opcode %02x %v matches the suite", suite.variant, opc, op.Name), t, func() {
+				failures, err := runSingleStepFile(filepath.Join(dir, fmt.Sprintf("%02x.json", opc)), suite.variant)
+				So(err, ShouldBeNil)
+				if len(failures) > 3 {
+					failures = append(failures[:3], fmt.Sprintf("and %v more", len(failures)-3))
+				}
+				So(failures, ShouldBeEmpty)
+			})
+		}
+	}
+}
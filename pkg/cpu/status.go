@@ -0,0 +1,69 @@
+package cpu
+
+// Status is the 6502's processor status register P, packed the same way the 6502 pushes it: NV-BDIZC from bit 7 down.
+//
+// B and bit 5 don't exist in the real register, they only show up in the copy of it that gets pushed on the stack.
+type Status byte
+
+const ( // status flag masks
+	posC byte = 1 << iota
+	posZ
+	posI
+	posD // decimal mode, which the 2A03 doesn't have
+	posB
+	pos_ // unused
+	posV
+	posN
+)
+
+// The flags' bits in the status byte, for [Status.Flag] and [Status.SetFlag].
+const (
+	FlagC = posC // carry
+	FlagZ = posZ // zero
+	FlagI = posI // interrupt disable
+	FlagD = posD // decimal mode
+	FlagB = posB // break, only in a pushed copy of the status
+	FlagU = pos_ // bit 5, unused and set in a pushed copy of the status
+	FlagV = posV // overflow
+	FlagN = posN // negative
+)
+
+// Flag reports whether the flags in mask are set.
+func (status Status) Flag(mask byte) bool {
+	return byte(status)&mask != 0
+}
+
+// SetFlag sets or clears the flags in mask.
+func (status *Status) SetFlag(mask byte, on bool) {
+	if on {
+		*status |= Status(mask)
+	} else {
+		*status &^= Status(mask)
+	}
+}
+
+// Clear clears every flag.
+func (status *Status) Clear() {
+	*status = 0
+}
+
+// Get returns the 6502's status byte.
+func (status Status) Get() byte {
+	return byte(status)
+}
+
+// Set sets the flags from a 6502 status byte.
+func (status *Status) Set(b byte) {
+	*status = Status(b)
+}
+
+// String shows the flags as NV-BDIZC with the set flags in upper case, e.g. "nvUbdIzc".
+func (status Status) String() string {
+	flags := []byte("nvubdizc")
+	for i := range flags {
+		if status.Flag(posN >> i) {
+			flags[i] -= 'a' - 'A'
+		}
+	}
+	return string(flags)
+}
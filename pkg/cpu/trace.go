@@ -0,0 +1,174 @@
+package cpu
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The trace is one line per instruction in the format of nestest.log, the reference log of Kevin Horton's nestest ROM
+// run on Nintendulator, so a trace of nestest can be diffed against it line by line:
+//
+//	C000  4C F5 C5  JMP $C5F5                       A:00 X:00 Y:00 P:24 SP:FD PPU:  0, 21 CYC:7
+//	C6BD  04 A9    *NOP $A9 = 00                    ...
+//
+// Each line is the state before the instruction runs. Illegal opcodes have a * before the name, and the operand has the
+// effective address and the value there, read without side effects.
+// https://www.qmtpro.com/~nes/misc/nestest.log
+
+// traceNames are the illegal opcodes nestest.log calls something else.
+var traceNames = map[string]string{"ISC": "ISB", "USBC": "SBC"}
+
+// WithTrace writes a line for every instruction to w in the format of nestest.log, see [CPU.TraceLine].
+// Write errors are ignored, so w should be something like a [bufio.Writer] that's checked when it's flushed.
+func WithTrace(w io.Writer) Option {
+	return func(cpu *CPU) {
+		cpu.trace = w
+	}
+}
+
+// WithTracePPU sets where the trace gets the PPU's scanline and dot from.
+// Without it they're worked out from the cycle count, as if the PPU had started at scanline 0 dot 0 and never skips a dot.
+func WithTracePPU(fn func() (scanline, dot int)) Option {
+	return func(cpu *CPU) {
+		cpu.tracePPU = fn
+	}
+}
+
+// traceInstruction writes the trace line of the instruction about to run.
+func (cpu *CPU) traceInstruction() {
+	fmt.Fprintln(cpu.trace, cpu.TraceLine())
+}
+
+// TraceLine gives the instruction at the pc and the registers in the format of nestest.log.
+func (cpu *CPU) TraceLine() string {
+	op := &cpu.opcodes[cpu.peek(cpu.pc)]
+	bytes := make([]string, op.Size)
+	for i := range bytes {
+		bytes[i] = fmt.Sprintf("%02X", cpu.peek(cpu.pc+uint16(i)))
+	}
+	mark := ' '
+	if op.Illegal {
+		mark = '*'
+	}
+
+	scanline, dot := cpu.ppuPosition()
+	return fmt.Sprintf("%04X  %-8v %c%-31v A:%02X X:%02X Y:%02X P:%02X SP:%02X PPU:%3d,%3d CYC:%v",
+		cpu.pc, strings.Join(bytes, " "), mark, cpu.traceOperation(op),
+		cpu.a, cpu.x, cpu.y, (byte(cpu.status)|pos_)&^posB, cpu.s, scanline, dot, cpu.cycles)
+}
+
+// ppuPosition is the PPU's scanline and dot for the trace.
+func (cpu *CPU) ppuPosition() (scanline, dot int) {
+	if cpu.tracePPU != nil {
+		return cpu.tracePPU()
+	}
+	dots := cpu.cycles * 3 // the PPU runs 3 dots per CPU cycle
+	return int(dots / 341 % 262), int(dots % 341)
+}
+
+// traceOperation disassembles the instruction at the pc with its effective address and the value there.
+func (cpu *CPU) traceOperation(op *opcode) string {
+	name := op.Name
+	if n, ok := traceNames[name]; ok {
+		name = n
+	}
+	arg := cpu.peek(cpu.pc + 1)
+	arg16 := uint16(cpu.peek(cpu.pc+2))<<8 | uint16(arg)
+	next := cpu.pc + op.Size
+
+	var operand string
+	switch op.Mode {
+	case implicit:
+		return name
+	case accumulator:
+		operand = "A"
+	case immediate:
+		operand = fmt.Sprintf("#$%02X", arg)
+	case zeroPage:
+		operand = fmt.Sprintf("$%02X = %02X", arg, cpu.peek(uint16(arg)))
+	case zeroPageX:
+		addr := arg + cpu.x
+		operand = fmt.Sprintf("$%02X,X @ %02X = %02X", arg, addr, cpu.peek(uint16(addr)))
+	case zeroPageY:
+		addr := arg + cpu.y
+		operand = fmt.Sprintf("$%02X,Y @ %02X = %02X", arg, addr, cpu.peek(uint16(addr)))
+	case relative:
+		operand = fmt.Sprintf("$%04X", next+uint16(int8(arg)))
+	case absolute:
+		if op.Name == "JMP" || op.Name == "JSR" {
+			operand = fmt.Sprintf("$%04X", arg16)
+		} else {
+			operand = fmt.Sprintf("$%04X = %02X", arg16, cpu.peek(arg16))
+		}
+	case absoluteX:
+		addr := arg16 + uint16(cpu.x)
+		operand = fmt.Sprintf("$%04X,X @ %04X = %02X", arg16, addr, cpu.peek(addr))
+	case absoluteY:
+		addr := arg16 + uint16(cpu.y)
+		operand = fmt.Sprintf("$%04X,Y @ %04X = %02X", arg16, addr, cpu.peek(addr))
+	case indirect:
+		operand = fmt.Sprintf("($%04X) = %04X", arg16, cpu.peekPointer(arg16, cpu.variant != Variant65C02))
+	case indirectX:
+		zp := arg + cpu.x
+		addr := cpu.peekPointer(uint16(zp), true)
+		operand = fmt.Sprintf("($%02X,X) @ %02X = %04X = %02X", arg, zp, addr, cpu.peek(addr))
+	case indirectY:
+		base := cpu.peekPointer(uint16(arg), true)
+		addr := base + uint16(cpu.y)
+		operand = fmt.Sprintf("($%02X),Y = %04X @ %04X = %02X", arg, base, addr, cpu.peek(addr))
+	case zeroPageIndirect:
+		addr := cpu.peekPointer(uint16(arg), true)
+		operand = fmt.Sprintf("($%02X) = %04X = %02X", arg, addr, cpu.peek(addr))
+	case absoluteIndirectX:
+		ptr := arg16 + uint16(cpu.x)
+		operand = fmt.Sprintf("($%04X,X) @ %04X = %04X", arg16, ptr, cpu.peekPointer(ptr, false))
+	case zeroPageRelative:
+		target := next + uint16(int8(cpu.peek(cpu.pc+2)))
+		operand = fmt.Sprintf("$%02X = %02X, $%04X", arg, cpu.peek(uint16(arg)), target)
+	}
+	return name + " " + operand
+}
+
+// peekPointer peeks the little endian pointer at addr. If wrap is set the high byte comes from the same page,
+// like the zero page pointers and NMOS JMP ($xxFF).
+func (cpu *CPU) peekPointer(addr uint16, wrap bool) uint16 {
+	hiAddr := addr + 1
+	if wrap {
+		hiAddr = addr&0xFF00 | uint16(byte(addr)+1)
+	}
+	return uint16(cpu.peek(hiAddr))<<8 | uint16(cpu.peek(addr))
+}
+
+// TraceDivergence is where a trace first differs from the reference, see [DiffTrace].
+type TraceDivergence struct {
+	Line      int // from 1
+	Want, Got string
+}
+
+func (d *TraceDivergence) Error() string {
+	return fmt.Sprintf("trace diverges at line %v:\nwant: %v\n got: %v", d.Line, d.Want, d.Got)
+}
+
+// DiffTrace compares a trace against a reference log like nestest.log line by line.
+// If they differ it returns a [*TraceDivergence] for the first line that does, where a missing line is empty.
+// Trailing whitespace and Windows line endings are ignored.
+func DiffTrace(want, got io.Reader) error {
+	wants, gots := bufio.NewScanner(want), bufio.NewScanner(got)
+	for line := 1; ; line++ {
+		haveWant, haveGot := wants.Scan(), gots.Scan()
+		if !haveWant && !haveGot {
+			break
+		}
+		wantLine := strings.TrimRight(wants.Text(), " \t\r")
+		gotLine := strings.TrimRight(gots.Text(), " \t\r")
+		if wantLine != gotLine || haveWant != haveGot {
+			return &TraceDivergence{Line: line, Want: wantLine, Got: gotLine}
+		}
+	}
+	if err := wants.Err(); err != nil {
+		return err
+	}
+	return gots.Err()
+}
@@ -0,0 +1,159 @@
+package cpu
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTrace(t *testing.T) {
+	Convey("traces like nestest.log", t, func() {
+		var out strings.Builder
+		cpu := New(WithTrace(&out))
+		copy(cpu.ram()[0xC000:], []byte{0x4c, 0xf5, 0xc5})                                                 // JMP $C5F5
+		copy(cpu.ram()[0xC5F5:], []byte{0xa2, 0x00, 0x86, 0x00, 0x86, 0x10, 0x86, 0x11, 0x20, 0x2d, 0xc7}) // LDX #0, STX $00, STX $10, STX $11, JSR $C72D
+		cpu.SetPC(0xC000)
+
+		Convey("the start of nestest", func() {
+			for range 6 {
+				cpu.Step()
+			}
+			So(out.String(), ShouldEqual, ""+
+				"C000  4C F5 C5  JMP $C5F5                       A:00 X:00 Y:00 P:24 SP:FD PPU:  0, 21 CYC:7\n"+
+				"C5F5  A2 00     LDX #$00                        A:00 X:00 Y:00 P:24 SP:FD PPU:  0, 30 CYC:10\n"+
+				"C5F7  86 00     STX $00 = 00                    A:00 X:00 Y:00 P:26 SP:FD PPU:  0, 36 CYC:12\n"+
+				"C5F9  86 10     STX $10 = 00                    A:00 X:00 Y:00 P:26 SP:FD PPU:  0, 45 CYC:15\n"+
+				"C5FB  86 11     STX $11 = 00                    A:00 X:00 Y:00 P:26 SP:FD PPU:  0, 54 CYC:18\n"+
+				"C5FD  20 2D C7  JSR $C72D                       A:00 X:00 Y:00 P:26 SP:FD PPU:  0, 63 CYC:21\n")
+		})
+
+		Convey("the accurate executor traces the same", func() {
+			var accurateOut strings.Builder
+			accurate := New(WithAccurate(true), WithTrace(&accurateOut))
+			*accurate.ram() = *cpu.ram()
+			accurate.SetPC(0xC000)
+			for range 6 {
+				cpu.Step()
+				accurate.Step()
+			}
+			So(accurateOut.String(), ShouldEqual, out.String())
+		})
+
+		Convey("every addressing mode with its effective address", func() {
+			operation := func(program ...byte) string {
+				copy(cpu.ram()[0x0300:], program)
+				cpu.pc = 0x0300
+				return strings.TrimRight(cpu.TraceLine()[16:48], " ")
+			}
+			cpu.x, cpu.y = 0x02, 0x10
+			cpu.ram()[0x0022], cpu.ram()[0x0010] = 0xAB, 0xCD
+			cpu.ram()[0x0080], cpu.ram()[0x0081] = 0x00, 0x04 // pointer to $0400
+			cpu.ram()[0x00FF], cpu.ram()[0x0000] = 0x34, 0x12 // pointer at $FF wraps
+			cpu.ram()[0x0410], cpu.ram()[0x1244] = 0x99, 0x77
+
+			So(operation(0xea), ShouldEqual, "NOP")
+			So(operation(0x4a), ShouldEqual, "LSR A")
+			So(operation(0xa9, 0x05), ShouldEqual, "LDA #$05")
+			So(operation(0xa5, 0x22), ShouldEqual, "LDA $22 = AB")
+			So(operation(0xb5, 0x20), ShouldEqual, "LDA $20,X @ 22 = AB")
+			So(operation(0xb6, 0x00), ShouldEqual, "LDX $00,Y @ 10 = CD")
+			So(operation(0xd0, 0xfe), ShouldEqual, "BNE $0300")
+			So(operation(0xad, 0x10, 0x04), ShouldEqual, "LDA $0410 = 99")
+			So(operation(0x20, 0x10, 0x04), ShouldEqual, "JSR $0410")
+			So(operation(0xbd, 0x0e, 0x04), ShouldEqual, "LDA $040E,X @ 0410 = 99")
+			So(operation(0xb9, 0x00, 0x04), ShouldEqual, "LDA $0400,Y @ 0410 = 99")
+			So(operation(0x6c, 0x80, 0x00), ShouldEqual, "JMP ($0080) = 0400")
+			So(operation(0xa1, 0x7e), ShouldEqual, "LDA ($7E,X) @ 80 = 0400 = 00")
+			So(operation(0xb1, 0x80), ShouldEqual, "LDA ($80),Y = 0400 @ 0410 = 99")
+			So(operation(0xb1, 0xff), ShouldEqual, "LDA ($FF),Y = 1234 @ 1244 = 77")
+		})
+
+		Convey("illegal opcodes are marked and named like nestest.log", func() {
+			copy(cpu.ram()[0x0300:], []byte{0xe7, 0x22}) // ISC $22
+			cpu.pc = 0x0300
+			So(cpu.TraceLine(), ShouldStartWith, "0300  E7 22    *ISB $22 = 00 ")
+
+			copy(cpu.ram()[0x0300:], []byte{0xeb, 0x05}) // USBC #$05
+			So(cpu.TraceLine(), ShouldStartWith, "0300  EB 05    *SBC #$05 ")
+		})
+
+		Convey("the PPU position can come from the PPU", func() {
+			cpu := New(WithTracePPU(func() (int, int) { return 241, 1 }))
+			So(cpu.TraceLine(), ShouldContainSubstring, " PPU:241,  1 ")
+		})
+	})
+
+	Convey("diffs traces", t, func() {
+		want := "C000  a\nC001  b\nC002  c\n"
+
+		So(DiffTrace(strings.NewReader(want), strings.NewReader("C000  a  \r\nC001  b\nC002  c")), ShouldBeNil)
+
+		err := DiffTrace(strings.NewReader(want), strings.NewReader("C000  a\nC001  x\nC002  c\n"))
+		var divergence *TraceDivergence
+		So(errors.As(err, &divergence), ShouldBeTrue)
+		So(*divergence, ShouldResemble, TraceDivergence{Line: 2, Want: "C001  b", Got: "C001  x"})
+		So(err.Error(), ShouldEqual, "trace diverges at line 2:\nwant: C001  b\n got: C001  x")
+
+		err = DiffTrace(strings.NewReader(want), strings.NewReader("C000  a\n"))
+		So(errors.As(err, &divergence), ShouldBeTrue)
+		So(*divergence, ShouldResemble, TraceDivergence{Line: 2, Want: "C001  b"})
+	})
+}
+
+// TestNestest runs nestest.nes from $C000, its automated mode, against nestest.log.
+// The ROM and log aren't in the repo, put them in testdata to run it: https://www.qmtpro.com/~nes/misc/
+func TestNestest(t *testing.T) {
+	rom, err := os.ReadFile(filepath.Join("testdata", "nestest.nes"))
+	if err != nil {
+		t.Skip("testdata/nestest.nes is missing")
+	}
+	log, err := os.ReadFile(filepath.Join("testdata", "nestest.log"))
+	if err != nil {
+		t.Skip("testdata/nestest.log is missing")
+	}
+
+	Convey("nestest matches nestest.log", t, func() {
+		var out bytes.Buffer
+		bus := &nestestBus{}
+		cpu := New(WithBus(bus), WithTrace(&out))
+		prg := rom[16 : 16+0x4000] // mapper 0 with 16KiB of PRG ROM mirrored at $8000 and $C000
+		copy(bus.RAM[0x8000:], prg)
+		copy(bus.RAM[0xC000:], prg)
+		cpu.SetPC(0xC000)
+
+		for range bytes.Count(log, []byte("\n")) {
+			cpu.Step()
+		}
+		So(DiffTrace(bytes.NewReader(log), &out), ShouldBeNil)
+		So(cpu.Peek(0x02), ShouldEqual, 0) // the official opcodes' error code
+		So(cpu.Peek(0x03), ShouldEqual, 0) // the illegal opcodes'
+	})
+}
+
+// nestestBus is flat RAM except for the APU and I/O registers, which nestest.log was made with nothing behind.
+// They read as $FF and ignore writes.
+type nestestBus struct {
+	RAM
+}
+
+func (b *nestestBus) Read(addr uint16) byte {
+	if addr >= 0x4000 && addr <= 0x401F {
+		return 0xFF
+	}
+	return b.RAM.Read(addr)
+}
+
+func (b *nestestBus) Write(addr uint16, dat byte) {
+	if addr < 0x4000 || addr > 0x401F {
+		b.RAM.Write(addr, dat)
+	}
+}
+
+func (b *nestestBus) Peek(addr uint16) byte {
+	return b.Read(addr)
+}
@@ -0,0 +1,146 @@
+package cpu
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Variant is which member of the 6502 family the CPU emulates. Each has its own opcode table.
+type Variant int
+
+const (
+	// Variant2A03 is the NES's Ricoh 2A03, an NMOS 6502 with decimal mode cut out. The D flag is still there but does nothing.
+	Variant2A03 Variant = iota
+	// VariantNMOS is the original NMOS 6502 with decimal mode, where only the carry is valid after a decimal ADC or SBC.
+	VariantNMOS
+	// Variant65C02 is the WDC 65C02, the CMOS 6502 with new opcodes and modes, the JMP indirect bug fixed and valid flags in decimal mode.
+	// The illegal NMOS opcodes are all NOPs on it.
+	Variant65C02
+)
+
+func (v Variant) String() string {
+	switch v {
+	case Variant2A03:
+		return "2A03"
+	case VariantNMOS:
+		return "6502"
+	case Variant65C02:
+		return "65C02"
+	default:
+		return fmt.Sprintf("Variant(%d)", int(v))
+	}
+}
+
+// ParseVariant parses the name of a variant as [Variant.String] gives it, in any case.
+func ParseVariant(s string) (Variant, error) {
+	for _, v := range []Variant{Variant2A03, VariantNMOS, Variant65C02} {
+		if strings.EqualFold(s, v.String()) {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown variant %q", s)
+}
+
+// WithVariant makes the CPU emulate v instead of the 2A03.
+//
+// The accurate executor only knows the NMOS bus patterns so it can't be used with the 65C02, see [WithAccurate].
+func WithVariant(v Variant) Option {
+	return func(cpu *CPU) {
+		cpu.variant = v
+		cpu.initializeOpcodeTable()
+	}
+}
+
+// decimal reports whether ADC and SBC do BCD arithmetic, which needs both the D flag and a chip that has decimal mode.
+func (cpu *CPU) decimal() bool {
+	return cpu.status.Flag(posD) && cpu.variant != Variant2A03
+}
+
+// add is ADC of val into the accumulator, in BCD when [CPU.decimal].
+func (cpu *CPU) add(val byte) {
+	if cpu.decimal() {
+		cpu.addDecimal(val)
+		return
+	}
+	cpu.addWithCarry(val)
+}
+
+// subtract is SBC of val from the accumulator, in BCD when [CPU.decimal].
+func (cpu *CPU) subtract(val byte) {
+	if cpu.decimal() {
+		cpu.subtractDecimal(val)
+		return
+	}
+	// A-M-(1-C) == A+(255-M)+C == A+^M+C so subtraction is just addition of the ones' complement
+	cpu.addWithCarry(^val)
+}
+
+// addDecimal is ADC in decimal mode. Each nibble is a decimal digit and gets adjusted back into 0-9 as it carries.
+//
+// On the NMOS chips N and V come from the sum before the high digit is adjusted and Z from the binary sum.
+// The 65C02 sets N and Z from the result, and takes a cycle longer to do it.
+// http://www.6502.org/tutorials/decimal_mode.html#A
+func (cpu *CPU) addDecimal(val byte) {
+	a, b, c := int(cpu.a), int(val), 0
+	if cpu.status.Flag(posC) {
+		c = 1
+	}
+	lo := a&0x0F + b&0x0F + c
+	if lo >= 0x0A {
+		lo = (lo+0x06)&0x0F + 0x10
+	}
+	sum := a&0xF0 + b&0xF0 + lo
+	signed := int(int8(a&0xF0)) + int(int8(b&0xF0)) + lo // the unadjusted sum as a signed number for V
+	if sum >= 0xA0 {
+		sum += 0x60
+	}
+	cpu.status.SetFlag(posC, sum >= 0x100)
+	cpu.status.SetFlag(posV, signed < -128 || signed > 127)
+	cpu.a = byte(sum)
+	if cpu.variant == Variant65C02 {
+		cpu.setZN(cpu.a)
+		cpu.extraCycles += 1
+		return
+	}
+	cpu.setZ(byte(a + b + c))
+	cpu.setN(byte(signed))
+}
+
+// subtractDecimal is SBC in decimal mode.
+//
+// On the NMOS chips all the flags come from the binary subtraction.
+// The 65C02 sets N and Z from the result, and takes a cycle longer to do it.
+// http://www.6502.org/tutorials/decimal_mode.html#A
+func (cpu *CPU) subtractDecimal(val byte) {
+	a, b, borrow := int(cpu.a), int(val), 1
+	if cpu.status.Flag(posC) {
+		borrow = 0
+	}
+	binary := a - b - borrow
+	cpu.status.SetFlag(posC, binary >= 0)
+	cpu.status.SetFlag(posV, (a^b)&(a^binary)&0x80 != 0)
+
+	lo := a&0x0F - b&0x0F - borrow
+	if cpu.variant == Variant65C02 {
+		result := binary
+		if result < 0 {
+			result -= 0x60
+		}
+		if lo < 0 {
+			result -= 0x06
+		}
+		cpu.a = byte(result)
+		cpu.setZN(cpu.a)
+		cpu.extraCycles += 1
+		return
+	}
+	if lo < 0 {
+		lo = (lo-0x06)&0x0F - 0x10
+	}
+	result := a&0xF0 - b&0xF0 + lo
+	if result < 0 {
+		result -= 0x60
+	}
+	cpu.a = byte(result)
+	cpu.setZN(byte(binary))
+}
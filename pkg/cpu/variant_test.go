@@ -0,0 +1,127 @@
+package cpu
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseVariant(t *testing.T) {
+	Convey("should parse variant names", t, func() {
+		for _, v := range []Variant{Variant2A03, VariantNMOS, Variant65C02} {
+			parsed, err := ParseVariant(v.String())
+			So(err, ShouldBeNil)
+			So(parsed, ShouldEqual, v)
+		}
+		v, err := ParseVariant("65c02")
+		So(err, ShouldBeNil)
+		So(v, ShouldEqual, Variant65C02)
+		_, err = ParseVariant("z80")
+		So(err, ShouldBeError, `unknown variant "z80"`)
+	})
+}
+
+func TestDecimal(t *testing.T) {
+	Convey("should do decimal arithmetic", t, func() {
+		// run does A op val with the carry in C and decimal mode on, returning the cycles it took
+		run := func(cpu *CPU, op byte, a, val byte, c bool) int {
+			copy(cpu.ram()[0x0200:], []byte{op, val})
+			cpu.pc, cpu.a = 0x0200, a
+			cpu.status.Set(posD)
+			cpu.status.SetFlag(posC, c)
+			return stepCycles(cpu.Step())
+		}
+		const adc, sbc, arr = 0x69, 0xE9, 0x6B
+
+		Convey("the 2A03 ignores the D flag", func() {
+			cpu := New()
+			So(run(cpu, adc, 0x09, 0x01, false), ShouldEqual, 2)
+			So(cpu.a, ShouldEqual, 0x0A)
+			run(cpu, sbc, 0x10, 0x01, true)
+			So(cpu.a, ShouldEqual, 0x0F)
+		})
+
+		Convey("nmos", func() {
+			cpu := New(WithVariant(VariantNMOS))
+
+			Convey("adds digits", func() {
+				So(run(cpu, adc, 0x46, 0x12, false), ShouldEqual, 2)
+				So(cpu.a, ShouldEqual, 0x58)
+				So(cpu.status.Flag(posC), ShouldBeFalse)
+
+				run(cpu, adc, 0x58, 0x46, true)
+				So(cpu.a, ShouldEqual, 0x05)
+				So(cpu.status.Flag(posC), ShouldBeTrue)
+			})
+
+			Convey("sets Z from the binary sum and N and V before the high digit is adjusted", func() {
+				run(cpu, adc, 0x99, 0x01, false)
+				So(cpu.a, ShouldEqual, 0x00)
+				So(cpu.status.Flag(posC), ShouldBeTrue)
+				So(cpu.status.Flag(posZ), ShouldBeFalse) // 0x99+0x01 is 0x9A
+				So(cpu.status.Flag(posN), ShouldBeTrue)
+
+				run(cpu, adc, 0x79, 0x00, true)
+				So(cpu.a, ShouldEqual, 0x80)
+				So(cpu.status.Flag(posV), ShouldBeTrue)
+			})
+
+			Convey("subtracts digits with the binary flags", func() {
+				So(run(cpu, sbc, 0x46, 0x12, true), ShouldEqual, 2)
+				So(cpu.a, ShouldEqual, 0x34)
+				So(cpu.status.Flag(posC), ShouldBeTrue)
+
+				run(cpu, sbc, 0x12, 0x21, true)
+				So(cpu.a, ShouldEqual, 0x91)
+				So(cpu.status.Flag(posC), ShouldBeFalse)
+
+				run(cpu, sbc, 0x00, 0x00, false)
+				So(cpu.a, ShouldEqual, 0x99)
+				So(cpu.status.Flag(posC), ShouldBeFalse)
+				So(cpu.status.Flag(posN), ShouldBeTrue) // from 0xFF
+				So(cpu.status.Flag(posZ), ShouldBeFalse)
+			})
+
+			Convey("arr fixes up each digit", func() {
+				run(cpu, arr, 0xFF, 0xFF, false)
+				So(cpu.a, ShouldEqual, 0xD5)
+				So(cpu.status.Flag(posC), ShouldBeTrue)
+				So(cpu.status.Flag(posV), ShouldBeFalse)
+				So(cpu.status.Flag(posN), ShouldBeFalse)
+			})
+		})
+
+		Convey("65C02", func() {
+			cpu := New(WithVariant(Variant65C02))
+
+			Convey("sets valid flags and takes an extra cycle", func() {
+				So(run(cpu, adc, 0x99, 0x01, false), ShouldEqual, 3)
+				So(cpu.a, ShouldEqual, 0x00)
+				So(cpu.status.Flag(posC), ShouldBeTrue)
+				So(cpu.status.Flag(posZ), ShouldBeTrue)
+				So(cpu.status.Flag(posN), ShouldBeFalse)
+
+				So(run(cpu, sbc, 0x00, 0x01, true), ShouldEqual, 3)
+				So(cpu.a, ShouldEqual, 0x99)
+				So(cpu.status.Flag(posC), ShouldBeFalse)
+				So(cpu.status.Flag(posN), ShouldBeTrue)
+
+				run(cpu, sbc, 0x46, 0x12, true)
+				So(cpu.a, ShouldEqual, 0x34)
+			})
+
+			Convey("interrupts clear decimal mode", func() {
+				cpu.status.SetFlag(posD, true)
+				cpu.SetNMI(true)
+				cpu.Step()
+				So(cpu.status.Flag(posD), ShouldBeFalse)
+			})
+		})
+
+		Convey("variants print", func() {
+			So(Variant65C02.String(), ShouldEqual, "65C02")
+			So(VariantNMOS.String(), ShouldEqual, "6502")
+			So(Variant(7).String(), ShouldEqual, "Variant(7)")
+		})
+	})
+}
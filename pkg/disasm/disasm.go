@@ -0,0 +1,208 @@
+// Package disasm turns 6502 machine code back into assembly, using the cpu package's opcode tables.
+//
+// It writes the usual syntax for every addressing mode: LDA #$20, LDA $20,X, LDA ($20),Y, JMP ($FFFC), ASL A,
+// and the 65C02's LDA ($20), JMP ($1234,X) and BBR0 $20,$C010. Branch targets are resolved to absolute addresses,
+// and any address with a label is written as the label.
+package disasm
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"nes/pkg/cpu"
+)
+
+// Memory is where the code gets read from, e.g. a [cpu.CPU], a [cpu.RAM] or [Bytes].
+type Memory interface {
+	Peek(addr uint16) byte
+}
+
+// Bytes is a chunk of code loaded at Origin. Reading outside it gives 0.
+type Bytes struct {
+	Origin uint16
+	Data   []byte
+}
+
+// Peek returns the byte at addr.
+func (b Bytes) Peek(addr uint16) byte {
+	if i := int(addr - b.Origin); i < len(b.Data) {
+		return b.Data[i]
+	}
+	return 0
+}
+
+// Instruction is one decoded instruction.
+type Instruction struct {
+	Addr   uint16
+	Bytes  []byte
+	Opcode cpu.OpcodeInfo
+	// Operand is the operand bytes as a little endian value, or just the zero page byte for zp,rel.
+	Operand uint16
+	// Target is where a branch goes.
+	Target uint16
+}
+
+// String gives the instruction in assembly without labels.
+func (in Instruction) String() string {
+	return in.text(nil)
+}
+
+// text gives the instruction in assembly, writing the addresses in labels as their names.
+func (in Instruction) text(labels map[uint16]string) string {
+	addr := func(a uint16, zp bool) string {
+		if name, ok := labels[a]; ok {
+			return name
+		}
+		if zp {
+			return fmt.Sprintf("$%02X", a)
+		}
+		return fmt.Sprintf("$%04X", a)
+	}
+
+	var operand string
+	switch in.Opcode.Mode {
+	case "implicit":
+	case "accumulator":
+		operand = "A"
+	case "immediate":
+		operand = fmt.Sprintf("#$%02X", in.Operand)
+	case "zeroPage":
+		operand = addr(in.Operand, true)
+	case "zeroPageX":
+		operand = addr(in.Operand, true) + ",X"
+	case "zeroPageY":
+		operand = addr(in.Operand, true) + ",Y"
+	case "relative":
+		operand = addr(in.Target, false)
+	case "absolute":
+		operand = addr(in.Operand, false)
+	case "absoluteX":
+		operand = addr(in.Operand, false) + ",X"
+	case "absoluteY":
+		operand = addr(in.Operand, false) + ",Y"
+	case "indirect":
+		operand = "(" + addr(in.Operand, false) + ")"
+	case "indirectX":
+		operand = "(" + addr(in.Operand, true) + ",X)"
+	case "indirectY":
+		operand = "(" + addr(in.Operand, true) + "),Y"
+	case "zeroPageIndirect":
+		operand = "(" + addr(in.Operand, true) + ")"
+	case "absoluteIndirectX":
+		operand = "(" + addr(in.Operand, false) + ",X)"
+	case "zeroPageRelative":
+		operand = addr(in.Operand, true) + "," + addr(in.Target, false)
+	}
+	if operand == "" {
+		return in.Opcode.Name
+	}
+	return in.Opcode.Name + " " + operand
+}
+
+// Option configures a Disassembler made by [New].
+type Option func(*Disassembler)
+
+// WithVariant decodes the opcodes of v instead of the 2A03.
+func WithVariant(v cpu.Variant) Option {
+	return func(d *Disassembler) {
+		d.opcodes = cpu.Opcodes(v)
+	}
+}
+
+// WithLabels writes the addresses in labels as their names.
+func WithLabels(labels map[uint16]string) Option {
+	return func(d *Disassembler) {
+		for addr, name := range labels {
+			d.labels[addr] = name
+		}
+	}
+}
+
+// Disassembler decodes and prints instructions.
+type Disassembler struct {
+	opcodes [256]cpu.OpcodeInfo
+	labels  map[uint16]string
+}
+
+// New makes a Disassembler. By default it decodes the 2A03's opcodes and has no labels.
+func New(opts ...Option) *Disassembler {
+	d := &Disassembler{opcodes: cpu.Opcodes(cpu.Variant2A03), labels: map[uint16]string{}}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// SetLabel names addr, which replaces the address wherever it's an operand.
+func (d *Disassembler) SetLabel(addr uint16, name string) {
+	d.labels[addr] = name
+}
+
+// Decode decodes the instruction at addr.
+func (d *Disassembler) Decode(mem Memory, addr uint16) Instruction {
+	op := d.opcodes[mem.Peek(addr)]
+	in := Instruction{Addr: addr, Opcode: op}
+	for i := 0; i < op.Size; i++ {
+		in.Bytes = append(in.Bytes, mem.Peek(addr+uint16(i)))
+	}
+	next := addr + uint16(op.Size)
+	switch {
+	case op.Mode == "zeroPageRelative":
+		in.Operand = uint16(in.Bytes[1])
+		in.Target = next + uint16(int8(in.Bytes[2]))
+	case op.Size == 2:
+		in.Operand = uint16(in.Bytes[1])
+		if op.Mode == "relative" {
+			in.Target = next + uint16(int8(in.Bytes[1]))
+		}
+	case op.Size == 3:
+		in.Operand = uint16(in.Bytes[2])<<8 | uint16(in.Bytes[1])
+	}
+	return in
+}
+
+// Range decodes the instructions from start through end. The last one may run past end.
+func (d *Disassembler) Range(mem Memory, start, end uint16) []Instruction {
+	ins := []Instruction{}
+	for addr := int(start); addr <= int(end); {
+		in := d.Decode(mem, uint16(addr))
+		ins = append(ins, in)
+		addr += len(in.Bytes)
+	}
+	return ins
+}
+
+// Format gives the instruction in assembly, with labels.
+func (d *Disassembler) Format(in Instruction) string {
+	return in.text(d.labels)
+}
+
+// Line gives the instruction as a line of a listing, with its address and bytes:
+//
+//	C000  A2 FF     LDX #$FF
+func (d *Disassembler) Line(in Instruction) string {
+	bytes := []string{}
+	for _, b := range in.Bytes {
+		bytes = append(bytes, fmt.Sprintf("%02X", b))
+	}
+	return fmt.Sprintf("%04X  %-8v  %v", in.Addr, strings.Join(bytes, " "), d.Format(in))
+}
+
+// Write writes a listing of ins, see [Disassembler.Line], with a line for every label:
+//
+//	reset:
+//	C000  A2 FF     LDX #$FF
+func (d *Disassembler) Write(w io.Writer, ins []Instruction) error {
+	for _, in := range ins {
+		if name, ok := d.labels[in.Addr]; ok {
+			if _, err := fmt.Fprintf(w, "%v:\n", name); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, d.Line(in)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package disasm
+
+import (
+	"strings"
+	"testing"
+
+	"nes/pkg/cpu"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDisassemble(t *testing.T) {
+	Convey("disassembles", t, func() {
+		d := New()
+		text := func(code ...byte) string {
+			return d.Format(d.Decode(Bytes{Origin: 0xC000, Data: code}, 0xC000))
+		}
+
+		Convey("every addressing mode", func() {
+			So(text(0xea), ShouldEqual, "NOP")
+			So(text(0x0a), ShouldEqual, "ASL A")
+			So(text(0xa9, 0x20), ShouldEqual, "LDA #$20")
+			So(text(0xa5, 0x20), ShouldEqual, "LDA $20")
+			So(text(0xb5, 0x20), ShouldEqual, "LDA $20,X")
+			So(text(0xb6, 0x20), ShouldEqual, "LDX $20,Y")
+			So(text(0xad, 0x34, 0x12), ShouldEqual, "LDA $1234")
+			So(text(0xbd, 0x34, 0x12), ShouldEqual, "LDA $1234,X")
+			So(text(0xb9, 0x34, 0x12), ShouldEqual, "LDA $1234,Y")
+			So(text(0x6c, 0xfc, 0xff), ShouldEqual, "JMP ($FFFC)")
+			So(text(0xa1, 0x20), ShouldEqual, "LDA ($20,X)")
+			So(text(0xb1, 0x20), ShouldEqual, "LDA ($20),Y")
+			So(text(0xa7, 0x20), ShouldEqual, "LAX $20")
+		})
+
+		Convey("branches to absolute addresses", func() {
+			So(text(0xd0, 0x10), ShouldEqual, "BNE $C012")
+			So(text(0xd0, 0xfe), ShouldEqual, "BNE $C000")
+			So(text(0x10, 0x80), ShouldEqual, "BPL $BF82")
+		})
+
+		Convey("the 65C02's modes", func() {
+			d = New(WithVariant(cpu.Variant65C02))
+			So(text(0xb2, 0x20), ShouldEqual, "LDA ($20)")
+			So(text(0x7c, 0x34, 0x12), ShouldEqual, "JMP ($1234,X)")
+			So(text(0x0f, 0x20, 0x03), ShouldEqual, "BBR0 $20,$C006")
+			So(text(0x1a), ShouldEqual, "INC A")
+			So(text(0x80, 0xfe), ShouldEqual, "BRA $C000")
+		})
+
+		Convey("with labels", func() {
+			d = New(WithLabels(map[uint16]string{0xC000: "loop", 0x0020: "ptr"}))
+			d.SetLabel(0xFFFC, "resetVector")
+			So(text(0xd0, 0xfe), ShouldEqual, "BNE loop")
+			So(text(0xb1, 0x20), ShouldEqual, "LDA (ptr),Y")
+			So(text(0x6c, 0xfc, 0xff), ShouldEqual, "JMP (resetVector)")
+			So(text(0xa9, 0x20), ShouldEqual, "LDA #$20") // immediates aren't addresses
+		})
+
+		Convey("a range as a listing", func() {
+			d.SetLabel(0xC002, "loop")
+			code := Bytes{Origin: 0xC000, Data: []byte{0xa2, 0x05, 0xca, 0xd0, 0xfd, 0x00, 0x00}}
+			ins := d.Range(code, 0xC000, 0xC005)
+			So(ins, ShouldHaveLength, 4)
+			So(ins[3].Bytes, ShouldResemble, []byte{0x00, 0x00})
+
+			var b strings.Builder
+			So(d.Write(&b, ins), ShouldBeNil)
+			So(b.String(), ShouldEqual, ""+
+				"C000  A2 05     LDX #$05\n"+
+				"loop:\n"+
+				"C002  CA        DEX\n"+
+				"C003  D0 FD     BNE loop\n"+
+				"C005  00 00     BRK\n")
+		})
+
+		Convey("a range up to the end of memory", func() {
+			ins := d.Range(Bytes{Origin: 0xFFFE, Data: []byte{0xea, 0xea}}, 0xFFFE, 0xFFFF)
+			So(ins, ShouldHaveLength, 2)
+		})
+	})
+}